@@ -0,0 +1,196 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL is TTL of reference data endpoints in DefaultCacheTTLs.
+const DefaultCacheTTL = time.Hour
+
+type (
+	// ResponseCache caches successful GET responses of endpoints with TTL, concurrent identical requests
+	// are sent once. Use its Middleware with WithMiddleware option before retry middleware.
+	ResponseCache struct {
+		ttls map[string]time.Duration
+
+		mu       sync.Mutex
+		entries  map[string]cacheEntry
+		inflight map[string]*cacheCall
+		stats    CacheStats
+		// generation is changed by invalidation, responses of requests sent before it aren't cached.
+		generation uint64
+	}
+
+	// CacheStats counters of ResponseCache.
+	CacheStats struct {
+		Hits   uint64
+		Misses uint64
+		// Shared is count of requests which waited for identical request in flight instead of sending.
+		Shared uint64
+		// Entries is count of cached responses including expired ones which aren't requested since expiration.
+		Entries int
+	}
+
+	cacheEntry struct {
+		endpoint string
+		data     []byte
+		expires  time.Time
+	}
+
+	// cacheCall is request in flight, data and err are set before done is closed.
+	cacheCall struct {
+		done       chan struct{}
+		generation uint64
+		data       []byte
+		err        error
+	}
+)
+
+// DefaultCacheTTLs returns TTLs of reference data endpoints: instrument lists and search by FIGI and ticker.
+func DefaultCacheTTLs() map[string]time.Duration {
+	return map[string]time.Duration{
+		"Stocks":             DefaultCacheTTL,
+		"Bonds":              DefaultCacheTTL,
+		"ETFs":               DefaultCacheTTL,
+		"Currencies":         DefaultCacheTTL,
+		"InstrumentByFIGI":   DefaultCacheTTL,
+		"InstrumentByTicker": DefaultCacheTTL,
+	}
+}
+
+// NewResponseCache returns cache of endpoints with TTLs by RequestInfo.Endpoint, DefaultCacheTTLs if ttls is nil.
+// Other endpoints, e.g. orders and portfolio, aren't cached.
+func NewResponseCache(ttls map[string]time.Duration) *ResponseCache {
+	if ttls == nil {
+		ttls = DefaultCacheTTLs()
+	}
+
+	return &ResponseCache{
+		ttls:     ttls,
+		entries:  make(map[string]cacheEntry),
+		inflight: make(map[string]*cacheCall),
+	}
+}
+
+// Middleware returns middleware serving cached responses.
+func (c *ResponseCache) Middleware() Middleware {
+	return func(next RestHandler) RestHandler {
+		return func(ctx context.Context, req *RestRequest) error {
+			ttl, ok := c.ttls[req.Endpoint]
+			if !ok || ttl <= 0 || req.Method != http.MethodGet || req.Unmarshal == nil {
+				return next(ctx, req)
+			}
+
+			data, fetched, err := c.get(ctx, req, ttl, next)
+			if err != nil || fetched {
+				return err
+			}
+
+			return json.Unmarshal(data, req.Unmarshal)
+		}
+	}
+}
+
+// Invalidate removes cached responses of endpoints.
+func (c *ResponseCache) Invalidate(endpoints ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	remove := make(map[string]bool, len(endpoints))
+	for _, e := range endpoints {
+		remove[e] = true
+	}
+	for key, entry := range c.entries {
+		if remove[entry.endpoint] {
+			delete(c.entries, key)
+		}
+	}
+	c.generation++
+}
+
+// InvalidateAll removes all cached responses.
+func (c *ResponseCache) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]cacheEntry)
+	c.generation++
+}
+
+// Stats returns cache counters.
+func (c *ResponseCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = len(c.entries)
+
+	return stats
+}
+
+// get returns cached response, response of identical request in flight or sends request by next.
+// In the last case fetched is set, response is already decoded into req.Unmarshal.
+func (c *ResponseCache) get(ctx context.Context, req *RestRequest, ttl time.Duration, next RestHandler) (data []byte, fetched bool, err error) {
+	key := req.URL
+
+	for {
+		c.mu.Lock()
+		if entry, ok := c.entries[key]; ok {
+			if time.Now().Before(entry.expires) {
+				c.stats.Hits++
+				c.mu.Unlock()
+				return entry.data, false, nil
+			}
+			delete(c.entries, key)
+		}
+
+		if call, ok := c.inflight[key]; ok {
+			c.stats.Shared++
+			c.mu.Unlock()
+
+			select {
+			case <-ctx.Done():
+				return nil, false, ctx.Err()
+			case <-call.done:
+			}
+			// Request of other caller is cancelled by its context, try again by own one.
+			if errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded) {
+				continue
+			}
+			return call.data, false, call.err
+		}
+
+		call := &cacheCall{done: make(chan struct{}), generation: c.generation}
+		c.inflight[key] = call
+		c.stats.Misses++
+		c.mu.Unlock()
+
+		return nil, true, c.fetch(ctx, req, key, ttl, call, next)
+	}
+}
+
+// fetch sends request by next and shares response encoded from req.Unmarshal with waiting callers.
+func (c *ResponseCache) fetch(ctx context.Context, req *RestRequest, key string, ttl time.Duration, call *cacheCall, next RestHandler) error {
+	call.err = next(ctx, req)
+	if call.err == nil {
+		if call.data, call.err = json.Marshal(req.Unmarshal); call.err != nil {
+			call.err = fmt.Errorf("encode cached response: %w", call.err)
+		}
+	}
+
+	c.mu.Lock()
+	delete(c.inflight, key)
+	if call.err == nil && call.generation == c.generation {
+		c.entries[key] = cacheEntry{endpoint: req.Endpoint, data: call.data, expires: time.Now().Add(ttl)}
+	}
+	c.mu.Unlock()
+	close(call.done)
+
+	return call.err
+}
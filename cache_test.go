@@ -0,0 +1,81 @@
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResponseCacheConcurrent(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"trackingId":"abc","payload":{"figi":"BBG000B9XRY4","ticker":"AAPL"}}`))
+	}))
+	defer srv.Close()
+
+	cache := NewResponseCache(nil)
+	tracer := &recordTracer{}
+	client := NewRestClient("token", WithURL(srv.URL), WithTracer(tracer), WithMiddleware(cache.Middleware()))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			instrument, err := client.InstrumentByFIGI(context.Background(), "BBG000B9XRY4")
+			if err != nil || instrument.Ticker != "AAPL" {
+				t.Errorf("got %+v, %v", instrument, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, err := client.InstrumentByFIGI(context.Background(), "BBG000B9XRY4"); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("server received %d requests, want 1", n)
+	}
+	stats := cache.Stats()
+	if stats.Misses != 1 || stats.Hits+stats.Shared != 10 || stats.Entries != 1 {
+		t.Fatalf("got %+v", stats)
+	}
+	for _, result := range tracer.results {
+		if result.TrackingID != "abc" {
+			t.Fatalf("tracking id of cached response is lost: %+v", result)
+		}
+	}
+
+	cache.Invalidate("InstrumentByFIGI")
+	if _, err := client.InstrumentByFIGI(context.Background(), "BBG000B9XRY4"); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("server received %d requests after invalidation, want 2", n)
+	}
+}
+
+func TestResponseCacheNotCachedEndpoint(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(`{"payload":[]}`))
+	}))
+	defer srv.Close()
+
+	client := NewRestClient("token", WithURL(srv.URL), WithMiddleware(NewResponseCache(nil).Middleware()))
+	for i := 0; i < 2; i++ {
+		if _, err := client.Orders(context.Background(), DefaultAccount); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("server received %d requests, want 2", n)
+	}
+}
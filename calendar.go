@@ -0,0 +1,517 @@
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dateLayout is layout of dates in holidays file.
+const dateLayout = "2006-01-02"
+
+// maxClosedDays limits search of the next session, e.g. long new year holidays.
+const maxClosedDays = 31
+
+var (
+	ErrUnknownExchange = errors.New("unknown exchange of instrument")
+	ErrNoSession       = errors.New("no trading session")
+	ErrNoHolidayData   = errors.New("no holidays data")
+)
+
+type (
+	Exchange    string
+	SessionKind string
+)
+
+const (
+	ExchangeMOEX Exchange = "MOEX"
+	ExchangeSPB  Exchange = "SPB"
+)
+
+const (
+	SessionOpeningAuction SessionKind = "OpeningAuction"
+	SessionMain           SessionKind = "Main"
+	SessionClosingAuction SessionKind = "ClosingAuction"
+	SessionEvening        SessionKind = "Evening"
+	SessionWeekend        SessionKind = "Weekend"
+)
+
+// Continuous reports whether session has continuous trading (candles are expected), auctions haven't.
+func (k SessionKind) Continuous() bool {
+	return k != SessionOpeningAuction && k != SessionClosingAuction
+}
+
+type (
+	// SessionSchedule is daily session, Start and End are offsets from midnight of exchange time zone.
+	// End may exceed 24h for sessions ending after midnight.
+	SessionSchedule struct {
+		Kind  SessionKind
+		Start time.Duration
+		End   time.Duration
+		// Since is the first day of the session, zero if session is always held.
+		Since time.Time
+	}
+
+	// ExchangeSchedule is sessions of trading days.
+	// Weekend sessions are held on Saturdays and Sundays which are not holidays or working days.
+	ExchangeSchedule struct {
+		Location *time.Location
+		Weekdays []SessionSchedule
+		Weekend  []SessionSchedule
+	}
+
+	// Session is trading session of the day.
+	Session struct {
+		Exchange Exchange
+		Kind     SessionKind
+		Start    time.Time
+		End      time.Time
+	}
+
+	// CandleGap is period without candles when exchange was open.
+	CandleGap struct {
+		FIGI string
+		From time.Time
+		To   time.Time
+	}
+
+	// TradingCalendar knows trading sessions and holidays of exchanges.
+	// It is safe for concurrent use.
+	TradingCalendar struct {
+		instruments InstrumentResolver
+
+		mu        sync.RWMutex
+		schedules map[Exchange]ExchangeSchedule
+		holidays  map[Exchange]map[string]bool
+		workdays  map[Exchange]map[string]bool // working weekend days
+		years     map[Exchange]map[int]bool    // years covered by holidays data
+		exchanges map[string]Exchange          // figi -> exchange
+		// strict makes dates of years without holidays data fail with ErrNoHolidayData.
+		strict bool
+	}
+
+	// holidaysFile is content of holidays file: {"MOEX": {"years": [2021], "holidays": ["2021-01-01"], "workdays": []}}.
+	holidaysFile map[Exchange]struct {
+		Years    []int    `json:"years"`
+		Holidays []string `json:"holidays"`
+		Workdays []string `json:"workdays"`
+	}
+)
+
+// DefaultSchedules returns schedules of MOEX and SPB stock markets in Moscow time.
+func DefaultSchedules() map[Exchange]ExchangeSchedule {
+	moscow := moscowLocation()
+
+	return map[Exchange]ExchangeSchedule{
+		ExchangeMOEX: {
+			Location: moscow,
+			Weekdays: []SessionSchedule{
+				{Kind: SessionOpeningAuction, Start: clock(9, 50), End: clock(10, 0)},
+				{Kind: SessionMain, Start: clock(10, 0), End: clock(18, 40)},
+				{Kind: SessionClosingAuction, Start: clock(18, 40), End: clock(18, 50)},
+				{Kind: SessionEvening, Start: clock(19, 5), End: clock(23, 50)},
+			},
+			Weekend: []SessionSchedule{
+				{Kind: SessionWeekend, Start: clock(10, 0), End: clock(19, 0), Since: time.Date(2025, time.March, 1, 0, 0, 0, 0, moscow)},
+			},
+		},
+		ExchangeSPB: {
+			Location: moscow,
+			Weekdays: []SessionSchedule{
+				{Kind: SessionMain, Start: clock(10, 0), End: clock(25, 45)},
+			},
+		},
+	}
+}
+
+// NewTradingCalendar returns calendar with DefaultSchedules and holidays bundled with sdk, see DefaultHolidays.
+// Dates of years which aren't covered by holidays data are treated by weekday schedule as if there were no holidays,
+// check coverage by HasHolidayData and load holidays by LoadHolidays, or use SetStrictHolidays to fail such dates.
+//
+// Instruments have no exchange in OpenAPI, so exchange of instrument is guessed by instruments (may be nil):
+// currencies and instruments in rubles are traded on MOEX, the others on SPB. Use SetExchange to set it explicitly.
+func NewTradingCalendar(instruments InstrumentResolver) *TradingCalendar {
+	c := &TradingCalendar{
+		instruments: instruments,
+		schedules:   DefaultSchedules(),
+		holidays:    make(map[Exchange]map[string]bool),
+		workdays:    make(map[Exchange]map[string]bool),
+		years:       make(map[Exchange]map[int]bool),
+		exchanges:   make(map[string]Exchange),
+	}
+
+	if err := c.LoadHolidays(strings.NewReader(DefaultHolidays())); err != nil {
+		panic("invalid default holidays: " + err.Error())
+	}
+
+	return c
+}
+
+// SetSchedule replaces schedule of exchange.
+func (c *TradingCalendar) SetSchedule(exchange Exchange, schedule ExchangeSchedule) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.schedules[exchange] = schedule
+}
+
+// SetStrictHolidays makes dates of years which aren't covered by holidays data fail with ErrNoHolidayData
+// instead of using weekday schedule without holidays.
+func (c *TradingCalendar) SetStrictHolidays(strict bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.strict = strict
+}
+
+// HasHolidayData reports whether holidays of exchange are known for year.
+func (c *TradingCalendar) HasHolidayData(exchange Exchange, year int) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.years[exchange][year]
+}
+
+// SetExchange sets exchange of instrument by FIGI.
+func (c *TradingCalendar) SetExchange(figi string, exchange Exchange) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.exchanges[figi] = exchange
+}
+
+// Exchange returns exchange of instrument by FIGI.
+func (c *TradingCalendar) Exchange(figi string) (Exchange, bool) {
+	c.mu.RLock()
+	exchange, ok := c.exchanges[figi]
+	c.mu.RUnlock()
+	if ok {
+		return exchange, true
+	}
+
+	if c.instruments == nil {
+		return "", false
+	}
+	instrument, ok := c.instruments.InstrumentByFIGI(figi)
+	if !ok {
+		return "", false
+	}
+	if instrument.Type == InstrumentTypeCurrency || instrument.Currency == RUB {
+		return ExchangeMOEX, true
+	}
+
+	return ExchangeSPB, true
+}
+
+// LoadHolidays adds holidays and working weekend days from JSON:
+//
+//	{"MOEX": {"years": [2022], "holidays": ["2022-01-03", "2022-01-07"], "workdays": ["2022-03-05"]}}
+//
+// Years are covered by the data, years of listed days are covered too. Holidays of year should be loaded at once.
+func (c *TradingCalendar) LoadHolidays(r io.Reader) error {
+	var file holidaysFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return fmt.Errorf("decode json: %w", err)
+	}
+
+	holidays := make(map[Exchange][]string, len(file))
+	workdays := make(map[Exchange][]string, len(file))
+	years := make(map[Exchange][]int, len(file))
+	for exchange, days := range file {
+		years[exchange] = days.Years
+		for _, list := range [][]string{days.Holidays, days.Workdays} {
+			for _, day := range list {
+				date, err := time.Parse(dateLayout, day)
+				if err != nil {
+					return fmt.Errorf("%s: %w", exchange, err)
+				}
+				years[exchange] = append(years[exchange], date.Year())
+			}
+		}
+		holidays[exchange] = days.Holidays
+		workdays[exchange] = days.Workdays
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	addDays(c.holidays, holidays)
+	addDays(c.workdays, workdays)
+	for exchange, list := range years {
+		if c.years[exchange] == nil {
+			c.years[exchange] = make(map[int]bool, len(list))
+		}
+		for _, year := range list {
+			c.years[exchange][year] = true
+		}
+	}
+
+	return nil
+}
+
+// LoadHolidaysFile adds holidays from file by path, see LoadHolidays.
+func (c *TradingCalendar) LoadHolidaysFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+
+	return c.LoadHolidays(f)
+}
+
+// IsTradingDay reports whether exchange has sessions on date of t in exchange time zone.
+// ErrNoHolidayData is returned in strict mode if year of the date isn't covered by holidays data.
+func (c *TradingCalendar) IsTradingDay(exchange Exchange, t time.Time) (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	sessions, err := c.sessions(exchange, t)
+
+	return len(sessions) > 0, err
+}
+
+// IsOpen reports whether exchange of instrument has session (including auctions) at t.
+func (c *TradingCalendar) IsOpen(figi string, t time.Time) (bool, error) {
+	_, err := c.SessionBounds(figi, t)
+	if errors.Is(err, ErrNoSession) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// SessionBounds returns session of instrument exchange at t, ErrNoSession if exchange is closed.
+func (c *TradingCalendar) SessionBounds(figi string, t time.Time) (Session, error) {
+	exchange, ok := c.Exchange(figi)
+	if !ok {
+		return Session{}, fmt.Errorf("%w %s", ErrUnknownExchange, figi)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	sessions, err := c.sessionsAt(exchange, t)
+	if err != nil {
+		return Session{}, err
+	}
+	for _, s := range sessions {
+		if !t.Before(s.Start) && t.Before(s.End) {
+			return s, nil
+		}
+	}
+
+	return Session{}, ErrNoSession
+}
+
+// NextOpen returns t if exchange of instrument is open at t, otherwise start of the next session.
+func (c *TradingCalendar) NextOpen(figi string, t time.Time) (time.Time, error) {
+	exchange, ok := c.Exchange(figi)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%w %s", ErrUnknownExchange, figi)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.nextOpen(exchange, t, false)
+}
+
+// CandleGaps returns periods without candles when exchange of instrument had continuous trading.
+// Candles should be of one instrument and interval sorted by time, e.g. result of RestClient.CandlesRange.
+// Intraday gaps are checked by sessions, daily ones by trading days, weekly and monthly candles have no gaps.
+func (c *TradingCalendar) CandleGaps(candles []Candle) ([]CandleGap, error) {
+	if len(candles) < 2 {
+		return nil, nil
+	}
+
+	figi, interval := candles[0].FIGI, candles[0].Interval
+	if interval == CandleInterval1Week || interval == CandleInterval1Month {
+		return nil, nil
+	}
+	d := candleDuration(interval)
+	if d == 0 {
+		return nil, fmt.Errorf("interval %q: %w", interval, ErrInvalidInterval)
+	}
+
+	exchange, ok := c.Exchange(figi)
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownExchange, figi)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var gaps []CandleGap
+	for i := 1; i < len(candles); i++ {
+		prev, next := candles[i-1].TS, candles[i].TS
+
+		if interval == CandleInterval1Day {
+			day, ok, err := c.tradingDayBetween(exchange, prev, next)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				gaps = append(gaps, CandleGap{FIGI: figi, From: day, To: next})
+			}
+		} else {
+			open, err := c.nextOpen(exchange, prev.Add(d), true)
+			if err != nil {
+				return nil, err
+			}
+			if open.Before(next) {
+				gaps = append(gaps, CandleGap{FIGI: figi, From: open, To: next})
+			}
+		}
+	}
+
+	return gaps, nil
+}
+
+// nextOpen returns t or start of the next session, only sessions with continuous trading if continuous.
+func (c *TradingCalendar) nextOpen(exchange Exchange, t time.Time, continuous bool) (time.Time, error) {
+	first := 0
+	if c.overnight(exchange) {
+		first = -1
+	}
+
+	for day := first; day <= maxClosedDays; day++ {
+		sessions, err := c.sessions(exchange, t.AddDate(0, 0, day))
+		if err != nil {
+			return time.Time{}, err
+		}
+		for _, s := range sessions {
+			if continuous && !s.Kind.Continuous() {
+				continue
+			}
+			if t.Before(s.End) {
+				if t.Before(s.Start) {
+					return s.Start, nil
+				}
+				return t, nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w in %d days after %s", ErrNoSession, maxClosedDays, t.Format(time.RFC3339))
+}
+
+// tradingDayBetween returns start of the first trading day strictly between dates of from and to.
+func (c *TradingCalendar) tradingDayBetween(exchange Exchange, from, to time.Time) (time.Time, bool, error) {
+	loc := c.location(exchange)
+	last := midnight(to.In(loc))
+	for day := midnight(from.In(loc)).AddDate(0, 0, 1); day.Before(last); day = day.AddDate(0, 0, 1) {
+		sessions, err := c.sessions(exchange, day)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		if len(sessions) > 0 {
+			return day, true, nil
+		}
+	}
+
+	return time.Time{}, false, nil
+}
+
+// sessionsAt returns sessions of date of t and sessions of the previous day if they may last after midnight.
+func (c *TradingCalendar) sessionsAt(exchange Exchange, t time.Time) ([]Session, error) {
+	sessions, err := c.sessions(exchange, t)
+	if err != nil || !c.overnight(exchange) {
+		return sessions, err
+	}
+
+	previous, err := c.sessions(exchange, t.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(previous, sessions...), nil
+}
+
+// overnight reports whether any session of exchange ends after midnight.
+func (c *TradingCalendar) overnight(exchange Exchange) bool {
+	schedule := c.schedules[exchange]
+	for _, s := range append(append([]SessionSchedule{}, schedule.Weekdays...), schedule.Weekend...) {
+		if s.End > 24*time.Hour {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sessions returns sessions of exchange on date of t in exchange time zone sorted by start.
+func (c *TradingCalendar) sessions(exchange Exchange, t time.Time) ([]Session, error) {
+	schedule, ok := c.schedules[exchange]
+	if !ok {
+		return nil, nil
+	}
+
+	day := midnight(t.In(c.location(exchange)))
+	if c.strict && !c.years[exchange][day.Year()] {
+		return nil, fmt.Errorf("%w of %s for %d", ErrNoHolidayData, exchange, day.Year())
+	}
+	date := day.Format(dateLayout)
+	if c.holidays[exchange][date] {
+		return nil, nil
+	}
+
+	daily := schedule.Weekdays
+	if weekday := day.Weekday(); (weekday == time.Saturday || weekday == time.Sunday) && !c.workdays[exchange][date] {
+		daily = schedule.Weekend
+	}
+
+	sessions := make([]Session, 0, len(daily))
+	for _, s := range daily {
+		if !s.Since.IsZero() && day.Before(midnight(s.Since.In(day.Location()))) {
+			continue
+		}
+		sessions = append(sessions, Session{
+			Exchange: exchange,
+			Kind:     s.Kind,
+			Start:    day.Add(s.Start),
+			End:      day.Add(s.End),
+		})
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Start.Before(sessions[j].Start) })
+
+	return sessions, nil
+}
+
+func (c *TradingCalendar) location(exchange Exchange) *time.Location {
+	if loc := c.schedules[exchange].Location; loc != nil {
+		return loc
+	}
+
+	return time.UTC
+}
+
+func addDays(dst map[Exchange]map[string]bool, src map[Exchange][]string) {
+	for exchange, days := range src {
+		if dst[exchange] == nil {
+			dst[exchange] = make(map[string]bool, len(days))
+		}
+		for _, day := range days {
+			dst[exchange][day] = true
+		}
+	}
+}
+
+// moscowLocation returns Moscow time zone, it has no daylight saving time since 2014.
+func moscowLocation() *time.Location {
+	return time.FixedZone("MSK", 3*60*60)
+}
+
+func clock(hour, minute int) time.Duration {
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,23 @@
+package sdk
+
+// DefaultHolidays returns holidays of exchanges loaded by NewTradingCalendar in LoadHolidays format.
+// It covers 2021 only, holidays of other years should be loaded by LoadHolidays,
+// otherwise weekday schedule without holidays is used for them, see TradingCalendar.HasHolidayData.
+func DefaultHolidays() string {
+	return `{
+  "MOEX": {
+    "years": [2021],
+    "holidays": [
+      "2021-01-01", "2021-01-07", "2021-02-23", "2021-03-08", "2021-05-03", "2021-05-10", "2021-06-14", "2021-11-04", "2021-12-31"
+    ],
+    "workdays": []
+  },
+  "SPB": {
+    "years": [2021],
+    "holidays": [
+      "2021-01-01", "2021-01-18", "2021-02-15", "2021-04-02", "2021-05-31", "2021-07-05", "2021-09-06", "2021-11-25", "2021-12-24"
+    ],
+    "workdays": []
+  }
+}`
+}
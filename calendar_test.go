@@ -0,0 +1,242 @@
+package sdk
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testFIGIMOEX = "BBG004730N88"
+	testFIGISPB  = "BBG000B9XRY4"
+)
+
+type testInstruments map[string]Instrument
+
+func (r testInstruments) InstrumentByFIGI(figi string) (Instrument, bool) {
+	i, ok := r[figi]
+	return i, ok
+}
+
+func testCalendar() *TradingCalendar {
+	return NewTradingCalendar(testInstruments{
+		testFIGIMOEX: {FIGI: testFIGIMOEX, Ticker: "SBER", Currency: RUB, Type: InstrumentTypeStock},
+		testFIGISPB:  {FIGI: testFIGISPB, Ticker: "AAPL", Currency: USD, Type: InstrumentTypeStock},
+	})
+}
+
+func msk(day, hour, minute int, month ...time.Month) time.Time {
+	m := time.March
+	if len(month) > 0 {
+		m = month[0]
+	}
+
+	return time.Date(2021, m, day, hour, minute, 0, 0, moscowLocation())
+}
+
+func TestTradingCalendarIsOpen(t *testing.T) {
+	calendar := testCalendar()
+
+	tests := []struct {
+		name string
+		figi string
+		at   time.Time
+		want bool
+	}{
+		{name: "MOEX main session", figi: testFIGIMOEX, at: msk(1, 12, 0), want: true},
+		{name: "MOEX opening auction", figi: testFIGIMOEX, at: msk(1, 9, 55), want: true},
+		{name: "MOEX between sessions", figi: testFIGIMOEX, at: msk(1, 18, 55), want: false},
+		{name: "MOEX before opening", figi: testFIGIMOEX, at: msk(1, 9, 0), want: false},
+		{name: "MOEX holiday", figi: testFIGIMOEX, at: msk(8, 12, 0), want: false},
+		{name: "MOEX weekend", figi: testFIGIMOEX, at: msk(6, 12, 0), want: false},
+		{name: "MOEX in UTC", figi: testFIGIMOEX, at: msk(1, 12, 0).UTC(), want: true},
+		{name: "SPB after midnight", figi: testFIGISPB, at: msk(2, 1, 0), want: true},
+		{name: "SPB closed", figi: testFIGISPB, at: msk(2, 2, 0), want: false},
+		{name: "SPB holiday", figi: testFIGISPB, at: msk(2, 12, 0, time.April), want: false},
+		{name: "SPB on MOEX holiday", figi: testFIGISPB, at: msk(8, 12, 0), want: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := calendar.IsOpen(tt.figi, tt.at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("IsOpen at %s = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTradingCalendarSessionBounds(t *testing.T) {
+	calendar := testCalendar()
+
+	session, err := calendar.SessionBounds(testFIGIMOEX, msk(1, 20, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Session{Exchange: ExchangeMOEX, Kind: SessionEvening, Start: msk(1, 19, 5), End: msk(1, 23, 50)}
+	if !session.Start.Equal(want.Start) || !session.End.Equal(want.End) || session.Kind != want.Kind || session.Exchange != want.Exchange {
+		t.Fatalf("got %+v, want %+v", session, want)
+	}
+
+	if _, err := calendar.SessionBounds(testFIGIMOEX, msk(1, 9, 0)); !errors.Is(err, ErrNoSession) {
+		t.Fatalf("want ErrNoSession, got %v", err)
+	}
+	if _, err := calendar.SessionBounds("UNKNOWNFIGI0", msk(1, 12, 0)); !errors.Is(err, ErrUnknownExchange) {
+		t.Fatalf("want ErrUnknownExchange, got %v", err)
+	}
+}
+
+func TestTradingCalendarNextOpen(t *testing.T) {
+	calendar := testCalendar()
+
+	// Saturday, Sunday and holiday on Monday 8 March
+	got, err := calendar.NextOpen(testFIGIMOEX, msk(6, 12, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := msk(9, 9, 50); !got.Equal(want) {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+
+	at := msk(9, 12, 0)
+	if got, err := calendar.NextOpen(testFIGIMOEX, at); err != nil || !got.Equal(at) {
+		t.Fatalf("got %s, %v, want %s", got, err, at)
+	}
+}
+
+func TestTradingCalendarCurrentYear(t *testing.T) {
+	calendar := testCalendar()
+
+	// weekday schedule without holidays is used for years without holidays data
+	monday := time.Date(2026, time.October, 19, 12, 0, 0, 0, moscowLocation())
+	if calendar.HasHolidayData(ExchangeMOEX, monday.Year()) {
+		t.Fatalf("holidays of %d aren't bundled", monday.Year())
+	}
+	if open, err := calendar.IsOpen(testFIGIMOEX, monday); err != nil || !open {
+		t.Fatalf("got %v, %v", open, err)
+	}
+	// weekend session of MOEX is held since March 2025
+	saturday := time.Date(2026, time.October, 17, 12, 0, 0, 0, moscowLocation())
+	session, err := calendar.SessionBounds(testFIGIMOEX, saturday)
+	if err != nil || session.Kind != SessionWeekend {
+		t.Fatalf("got %+v, %v", session, err)
+	}
+	if open, err := calendar.IsOpen(testFIGISPB, saturday); err != nil || open {
+		t.Fatalf("got %v, %v", open, err)
+	}
+
+	now := time.Now()
+	if _, err := calendar.NextOpen(testFIGIMOEX, now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := calendar.IsOpen(testFIGISPB, now); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTradingCalendarNoHolidayData(t *testing.T) {
+	calendar := testCalendar()
+	calendar.SetStrictHolidays(true)
+
+	if _, err := calendar.IsOpen(testFIGIMOEX, time.Date(2022, time.March, 1, 12, 0, 0, 0, moscowLocation())); !errors.Is(err, ErrNoHolidayData) {
+		t.Fatalf("want ErrNoHolidayData, got %v", err)
+	}
+	if _, err := calendar.IsTradingDay(ExchangeMOEX, time.Date(2020, time.March, 2, 12, 0, 0, 0, moscowLocation())); !errors.Is(err, ErrNoHolidayData) {
+		t.Fatalf("want ErrNoHolidayData, got %v", err)
+	}
+	// session of SPB on 31 December 2020 may last after midnight
+	if _, err := calendar.IsOpen(testFIGISPB, msk(1, 0, 30, time.January)); !errors.Is(err, ErrNoHolidayData) {
+		t.Fatalf("want ErrNoHolidayData, got %v", err)
+	}
+	// MOEX has no sessions after midnight, so the previous year isn't required
+	if open, err := calendar.IsOpen(testFIGIMOEX, msk(4, 12, 0, time.January)); err != nil || !open {
+		t.Fatalf("got %v, %v", open, err)
+	}
+
+	err := calendar.LoadHolidays(strings.NewReader(`{"MOEX": {"years": [2022], "holidays": ["2022-03-08"]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	open, err := calendar.IsOpen(testFIGIMOEX, time.Date(2022, time.March, 8, 12, 0, 0, 0, moscowLocation()))
+	if err != nil || open {
+		t.Fatalf("got %v, %v", open, err)
+	}
+}
+
+func TestTradingCalendarLoadHolidaysInvalid(t *testing.T) {
+	calendar := testCalendar()
+
+	if err := calendar.LoadHolidays(strings.NewReader(`{"MOEX": {"holidays": ["01.01.2022"]}}`)); err == nil {
+		t.Fatal("want error")
+	}
+	if err := calendar.LoadHolidays(strings.NewReader(`[]`)); err == nil {
+		t.Fatal("want error")
+	}
+}
+
+func TestTradingCalendarExchange(t *testing.T) {
+	calendar := testCalendar()
+
+	if exchange, ok := calendar.Exchange(testFIGIMOEX); !ok || exchange != ExchangeMOEX {
+		t.Fatalf("got %s, %v", exchange, ok)
+	}
+	if exchange, ok := calendar.Exchange(testFIGISPB); !ok || exchange != ExchangeSPB {
+		t.Fatalf("got %s, %v", exchange, ok)
+	}
+
+	calendar.SetExchange(testFIGISPB, ExchangeMOEX)
+	if exchange, ok := calendar.Exchange(testFIGISPB); !ok || exchange != ExchangeMOEX {
+		t.Fatalf("got %s, %v", exchange, ok)
+	}
+}
+
+func TestTradingCalendarCandleGaps(t *testing.T) {
+	calendar := testCalendar()
+
+	hourly := func(times ...time.Time) []Candle {
+		candles := make([]Candle, len(times))
+		for i, ts := range times {
+			candles[i] = Candle{FIGI: testFIGIMOEX, Interval: CandleInterval1Hour, TS: ts}
+		}
+		return candles
+	}
+
+	// 12:00 and 13:00 candles are missing, evening session follows the main one
+	gaps, err := calendar.CandleGaps(hourly(msk(1, 10, 0), msk(1, 11, 0), msk(1, 14, 0), msk(1, 15, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []CandleGap{{FIGI: testFIGIMOEX, From: msk(1, 12, 0), To: msk(1, 14, 0)}}; !reflect.DeepEqual(gaps, want) {
+		t.Fatalf("got %+v, want %+v", gaps, want)
+	}
+
+	// the last evening candle and the first candle of the next day
+	gaps, err = calendar.CandleGaps(hourly(msk(1, 23, 0), msk(2, 10, 0)))
+	if err != nil || len(gaps) != 0 {
+		t.Fatalf("got %+v, %v", gaps, err)
+	}
+
+	daily := []Candle{
+		{FIGI: testFIGIMOEX, Interval: CandleInterval1Day, TS: msk(5, 7, 0)},
+		// weekend and holiday
+		{FIGI: testFIGIMOEX, Interval: CandleInterval1Day, TS: msk(9, 7, 0)},
+		{FIGI: testFIGIMOEX, Interval: CandleInterval1Day, TS: msk(11, 7, 0)},
+	}
+	gaps, err = calendar.CandleGaps(daily)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []CandleGap{{FIGI: testFIGIMOEX, From: msk(10, 0, 0), To: msk(11, 7, 0)}}; !reflect.DeepEqual(gaps, want) {
+		t.Fatalf("got %+v, want %+v", gaps, want)
+	}
+
+	if _, err := calendar.CandleGaps([]Candle{{Interval: "7min"}, {Interval: "7min"}}); !errors.Is(err, ErrInvalidInterval) {
+		t.Fatalf("want ErrInvalidInterval, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// CandlesRange loads candles for period of any length.
+// Period is split into requests of max length allowed by API for the interval,
+// candles are returned in time order without duplicates.
+func (c *RestClient) CandlesRange(ctx context.Context, from, to time.Time, interval CandleInterval, figi string) ([]Candle, error) {
+	limit := candleRequestLimit(interval)
+	if limit == 0 {
+		return nil, fmt.Errorf("interval %q: %w", interval, ErrInvalidInterval)
+	}
+
+	var candles []Candle
+	for start := from; start.Before(to); start = start.Add(limit) {
+		end := start.Add(limit)
+		if end.After(to) {
+			end = to
+		}
+
+		chunk, err := c.Candles(ctx, start, end, interval, figi)
+		if err != nil {
+			return nil, fmt.Errorf("candles from %s to %s: %w", start.Format(time.RFC3339), end.Format(time.RFC3339), err)
+		}
+
+		for _, candle := range chunk {
+			if n := len(candles); n > 0 && !candle.TS.After(candles[n-1].TS) {
+				continue
+			}
+			candles = append(candles, candle)
+		}
+	}
+
+	return candles, nil
+}
+
+// candleRequestLimit returns max period of single candles request for interval or 0 for unknown interval.
+func candleRequestLimit(interval CandleInterval) time.Duration {
+	switch interval {
+	case CandleInterval1Min, CandleInterval2Min, CandleInterval3Min, CandleInterval5Min,
+		CandleInterval10Min, CandleInterval15Min, CandleInterval30Min:
+		return 24 * time.Hour
+	case CandleInterval1Hour, CandleInterval2Hour, CandleInterval4Hour:
+		return 7 * 24 * time.Hour
+	case CandleInterval1Day:
+		return 365 * 24 * time.Hour
+	case CandleInterval1Week:
+		return 2 * 365 * 24 * time.Hour
+	case CandleInterval1Month:
+		return 10 * 365 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// candleDuration returns duration of candle for intervals shorter than week or 0 for the others.
+func candleDuration(interval CandleInterval) time.Duration {
+	switch interval {
+	case CandleInterval1Min:
+		return time.Minute
+	case CandleInterval2Min:
+		return 2 * time.Minute
+	case CandleInterval3Min:
+		return 3 * time.Minute
+	case CandleInterval5Min:
+		return 5 * time.Minute
+	case CandleInterval10Min:
+		return 10 * time.Minute
+	case CandleInterval15Min:
+		return 15 * time.Minute
+	case CandleInterval30Min:
+		return 30 * time.Minute
+	case CandleInterval1Hour:
+		return time.Hour
+	case CandleInterval2Hour:
+		return 2 * time.Hour
+	case CandleInterval4Hour:
+		return 4 * time.Hour
+	case CandleInterval1Day:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	sdk "github.com/Tinkoff/invest-openapi-go-sdk"
+)
+
+// catalogMaxAge is age of cached instrument catalog after which it is refreshed.
+const catalogMaxAge = 24 * time.Hour
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("tinvest "+name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	return fs
+}
+
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	return nil
+}
+
+func runAccounts(ctx context.Context, a *app, args []string) error {
+	if err := parseFlags(newFlagSet("accounts"), args); err != nil {
+		return err
+	}
+
+	accounts, err := a.client.Accounts(ctx)
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"ID", "TYPE"}}
+	for _, acc := range accounts {
+		t.rows = append(t.rows, []string{acc.ID, string(acc.Type)})
+	}
+
+	return a.out.print(accounts, t)
+}
+
+func runPortfolio(ctx context.Context, a *app, args []string) error {
+	if err := parseFlags(newFlagSet("portfolio"), args); err != nil {
+		return err
+	}
+
+	portfolio, err := a.client.Portfolio(ctx, a.account)
+	if err != nil {
+		return err
+	}
+
+	positions := table{header: []string{"FIGI", "TICKER", "NAME", "TYPE", "BALANCE", "BLOCKED", "LOTS", "AVG PRICE", "YIELD", "CURRENCY"}}
+	for _, p := range portfolio.Positions {
+		positions.rows = append(positions.rows, []string{
+			p.FIGI, p.Ticker, p.Name, string(p.InstrumentType),
+			formatFloat(p.Balance), formatFloat(p.Blocked), formatInt(p.Lots),
+			formatFloat(p.AveragePositionPrice.Value), formatFloat(p.ExpectedYield.Value), string(p.AveragePositionPrice.Currency),
+		})
+	}
+
+	currencies := table{header: []string{"CURRENCY", "BALANCE", "BLOCKED"}}
+	for _, c := range portfolio.Currencies {
+		currencies.rows = append(currencies.rows, []string{string(c.Currency), formatFloat(c.Balance), formatFloat(c.Blocked)})
+	}
+
+	return a.out.print(portfolio, positions, currencies)
+}
+
+func runOrders(ctx context.Context, a *app, args []string) error {
+	action := "list"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+
+	switch action {
+	case "list":
+		return listOrders(ctx, a, args)
+	case "limit", "market":
+		return placeOrder(ctx, a, action, args)
+	case "cancel":
+		return cancelOrder(ctx, a, args)
+	case "cancel-all":
+		return cancelAllOrders(ctx, a, args)
+	default:
+		return fmt.Errorf("%w: unknown orders action %q, use list, limit, market, cancel or cancel-all", errUsage, action)
+	}
+}
+
+func listOrders(ctx context.Context, a *app, args []string) error {
+	if err := parseFlags(newFlagSet("orders list"), args); err != nil {
+		return err
+	}
+
+	orders, err := a.client.Orders(ctx, a.account)
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"ID", "FIGI", "OPERATION", "TYPE", "STATUS", "PRICE", "REQUESTED", "EXECUTED"}}
+	for _, o := range orders {
+		t.rows = append(t.rows, []string{
+			o.ID, o.FIGI, string(o.Operation), string(o.Type), string(o.Status),
+			formatFloat(o.Price), formatInt(o.RequestedLots), formatInt(o.ExecutedLots),
+		})
+	}
+
+	return a.out.print(orders, t)
+}
+
+func placeOrder(ctx context.Context, a *app, orderType string, args []string) error {
+	fs := newFlagSet("orders " + orderType)
+	figi := fs.String("figi", "", "instrument FIGI")
+	lots := fs.Int("lots", 0, "count of lots")
+	op := fs.String("op", "", "operation: buy or sell")
+	price := fs.Float64("price", 0, "limit price")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	operation, err := parseOperation(*op)
+	if err != nil {
+		return err
+	}
+	if *figi == "" || *lots <= 0 {
+		return fmt.Errorf("%w: -figi and positive -lots are required", errUsage)
+	}
+	if orderType == "limit" && *price <= 0 {
+		return fmt.Errorf("%w: positive -price is required for limit order", errUsage)
+	}
+	if err := a.checkConfirmed("placing order"); err != nil {
+		return err
+	}
+
+	var order sdk.PlacedOrder
+	if orderType == "limit" {
+		order, err = a.client.LimitOrder(ctx, a.account, *figi, *lots, operation, *price)
+	} else {
+		order, err = a.client.MarketOrder(ctx, a.account, *figi, *lots, operation)
+	}
+	if err != nil {
+		return err
+	}
+
+	t := table{
+		header: []string{"ID", "OPERATION", "STATUS", "REQUESTED", "EXECUTED", "COMMISSION", "REJECT REASON"},
+		rows: [][]string{{
+			order.ID, string(order.Operation), string(order.Status), formatInt(order.RequestedLots), formatInt(order.ExecutedLots),
+			formatFloat(order.Commission.Value), order.RejectReason,
+		}},
+	}
+
+	return a.out.print(order, t)
+}
+
+func cancelOrder(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("orders cancel")
+	id := fs.String("id", "", "order id")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *id == "" {
+		return fmt.Errorf("%w: -id is required", errUsage)
+	}
+	if err := a.checkConfirmed("cancelling order"); err != nil {
+		return err
+	}
+
+	if err := a.client.OrderCancel(ctx, a.account, *id); err != nil {
+		return err
+	}
+
+	return a.out.print([]string{*id}, table{header: []string{"CANCELLED"}, rows: [][]string{{*id}}})
+}
+
+func cancelAllOrders(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("orders cancel-all")
+	figi := fs.String("figi", "", "cancel orders of the instrument only")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if err := a.checkConfirmed("cancelling orders"); err != nil {
+		return err
+	}
+
+	orders, err := a.client.Orders(ctx, a.account)
+	if err != nil {
+		return err
+	}
+
+	cancelled := make([]string, 0, len(orders))
+	t := table{header: []string{"CANCELLED"}}
+	for _, o := range orders {
+		if *figi != "" && o.FIGI != *figi {
+			continue
+		}
+		if err := a.client.OrderCancel(ctx, a.account, o.ID); err != nil {
+			return fmt.Errorf("cancel order %s: %w", o.ID, err)
+		}
+		cancelled = append(cancelled, o.ID)
+		t.rows = append(t.rows, []string{o.ID})
+	}
+
+	return a.out.print(cancelled, t)
+}
+
+func runOperations(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("operations")
+	from := fs.String("from", "", "period start, RFC3339 or 2006-01-02, 30 days ago by default")
+	to := fs.String("to", "", "period end, RFC3339 or 2006-01-02, now by default")
+	figi := fs.String("figi", "", "instrument FIGI")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	start, end, err := parsePeriod(*from, *to, 30*24*time.Hour)
+	if err != nil {
+		return err
+	}
+
+	operations, err := a.client.Operations(ctx, a.account, start, end, *figi)
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"ID", "DATE", "TYPE", "STATUS", "FIGI", "QUANTITY", "PRICE", "PAYMENT", "CURRENCY", "COMMISSION"}}
+	for _, o := range operations {
+		t.rows = append(t.rows, []string{
+			o.ID, formatTime(o.DateTime), string(o.OperationType), string(o.Status), o.FIGI,
+			formatInt(o.QuantityExecuted), formatFloat(o.Price), formatFloat(o.Payment), string(o.Currency), formatFloat(o.Commission.Value),
+		})
+	}
+
+	return a.out.print(operations, t)
+}
+
+func runInstruments(ctx context.Context, a *app, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: use instruments search <query> or instruments get <figi|ticker|isin>", errUsage)
+	}
+
+	action, args := args[0], args[1:]
+	fs := newFlagSet("instruments " + action)
+	limit := fs.Int("limit", 10, "max count of search results")
+	cache := fs.String("cache", "", "file of instrument catalog cache, refreshed once a day")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: single query is required", errUsage)
+	}
+
+	catalog, err := loadCatalog(ctx, a.client, *cache)
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"FIGI", "TICKER", "ISIN", "NAME", "TYPE", "LOT", "MIN INCREMENT", "CURRENCY"}}
+	addRow := func(i sdk.Instrument) {
+		t.rows = append(t.rows, []string{
+			i.FIGI, i.Ticker, i.ISIN, i.Name, string(i.Type), formatInt(i.Lot), formatFloat(i.MinPriceIncrement), string(i.Currency),
+		})
+	}
+
+	switch action {
+	case "search":
+		matches := catalog.SearchInstruments(fs.Arg(0), *limit)
+		instruments := make([]sdk.Instrument, 0, len(matches))
+		for _, m := range matches {
+			instruments = append(instruments, m.Instrument)
+			addRow(m.Instrument)
+		}
+		return a.out.print(instruments, t)
+	case "get":
+		instruments := catalog.Lookup(fs.Arg(0))
+		if len(instruments) == 0 {
+			return fmt.Errorf("instrument %s: %w", fs.Arg(0), sdk.ErrNotFound)
+		}
+		for _, i := range instruments {
+			addRow(i)
+		}
+		return a.out.print(instruments, t)
+	default:
+		return fmt.Errorf("%w: unknown instruments action %q, use search or get", errUsage, action)
+	}
+}
+
+// loadCatalog returns catalog from cache file if it is fresh, otherwise catalog is refreshed and cached.
+func loadCatalog(ctx context.Context, client *sdk.RestClient, cache string) (*sdk.InstrumentCatalog, error) {
+	catalog := sdk.NewInstrumentCatalog(client)
+
+	if cache != "" {
+		if err := catalog.LoadFile(cache); err == nil && time.Since(catalog.UpdatedAt()) < catalogMaxAge {
+			return catalog, nil
+		}
+	}
+
+	if err := catalog.Refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	if cache != "" {
+		if err := catalog.SaveFile(cache); err != nil {
+			return nil, err
+		}
+	}
+
+	return catalog, nil
+}
+
+func runCandles(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("candles")
+	figi := fs.String("figi", "", "instrument FIGI")
+	interval := fs.String("interval", string(sdk.CandleInterval1Day), "candle interval: 1min, 5min, hour, day, week, month...")
+	from := fs.String("from", "", "period start, RFC3339 or 2006-01-02, 7 days ago by default, long periods are split into requests")
+	to := fs.String("to", "", "period end, RFC3339 or 2006-01-02, now by default")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *figi == "" {
+		return fmt.Errorf("%w: -figi is required", errUsage)
+	}
+
+	start, end, err := parsePeriod(*from, *to, 7*24*time.Hour)
+	if err != nil {
+		return err
+	}
+
+	candles, err := a.client.CandlesRange(ctx, start, end, sdk.CandleInterval(*interval), *figi)
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"TIME", "OPEN", "HIGH", "LOW", "CLOSE", "VOLUME"}}
+	for _, c := range candles {
+		t.rows = append(t.rows, []string{
+			formatTime(c.TS), formatFloat(c.OpenPrice), formatFloat(c.HighPrice), formatFloat(c.LowPrice), formatFloat(c.ClosePrice), formatFloat(c.Volume),
+		})
+	}
+
+	return a.out.print(candles, t)
+}
+
+func runOrderbook(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("orderbook")
+	figi := fs.String("figi", "", "instrument FIGI")
+	depth := fs.Int("depth", 10, "orderbook depth")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *figi == "" {
+		return fmt.Errorf("%w: -figi is required", errUsage)
+	}
+
+	book, err := a.client.Orderbook(ctx, *depth, *figi)
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"SIDE", "PRICE", "QUANTITY"}}
+	for i := len(book.Asks) - 1; i >= 0; i-- {
+		t.rows = append(t.rows, []string{"ask", formatFloat(book.Asks[i].Price), formatFloat(book.Asks[i].Quantity)})
+	}
+	for _, bid := range book.Bids {
+		t.rows = append(t.rows, []string{"bid", formatFloat(bid.Price), formatFloat(bid.Quantity)})
+	}
+
+	return a.out.print(book, t)
+}
+
+func parseOperation(op string) (sdk.OperationType, error) {
+	switch strings.ToLower(op) {
+	case "buy":
+		return sdk.BUY, nil
+	case "sell":
+		return sdk.SELL, nil
+	default:
+		return "", fmt.Errorf("%w: -op must be buy or sell", errUsage)
+	}
+}
+
+// parsePeriod parses period bounds, empty to is now and empty from is to minus defaultLength.
+func parsePeriod(from, to string, defaultLength time.Duration) (time.Time, time.Time, error) {
+	end := time.Now()
+	if to != "" {
+		t, err := parseTime(to)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		end = t
+	}
+
+	start := end.Add(-defaultLength)
+	if from != "" {
+		t, err := parseTime(from)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		start = t
+	}
+
+	return start, end, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: time %q must be RFC3339 or 2006-01-02", errUsage, s)
+	}
+
+	return t, nil
+}
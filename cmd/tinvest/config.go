@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	sdk "github.com/Tinkoff/invest-openapi-go-sdk"
+)
+
+var errNoToken = errors.New("token not found")
+
+type profile struct {
+	name         string
+	restURL      string
+	streamingURL string
+	sandbox      bool
+}
+
+func profileByName(name string) (profile, error) {
+	switch name {
+	case "sandbox":
+		return profile{
+			name:         name,
+			restURL:      sdk.SandboxRestAPIURL,
+			streamingURL: sdk.StreamingApiURL,
+			sandbox:      true,
+		}, nil
+	case "production":
+		return profile{
+			name:         name,
+			restURL:      sdk.RestAPIURL,
+			streamingURL: sdk.StreamingApiURL,
+		}, nil
+	default:
+		return profile{}, fmt.Errorf("unknown profile %q, use sandbox or production", name)
+	}
+}
+
+// loadToken returns token from the first found source:
+// file by tokenFile, TINVEST_<PROFILE>_TOKEN env, TINVEST_TOKEN env, ~/.config/tinvest/<profile>.token file.
+func loadToken(p profile, tokenFile string) (string, error) {
+	if tokenFile != "" {
+		return readToken(tokenFile)
+	}
+
+	if token := os.Getenv("TINVEST_" + strings.ToUpper(p.name) + "_TOKEN"); token != "" {
+		return strings.TrimSpace(token), nil
+	}
+	if token := os.Getenv("TINVEST_TOKEN"); token != "" {
+		return strings.TrimSpace(token), nil
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		path := filepath.Join(home, ".config", "tinvest", p.name+".token")
+		if _, err := os.Stat(path); err == nil {
+			return readToken(path)
+		}
+	}
+
+	return "", fmt.Errorf("%w for profile %s: set TINVEST_%s_TOKEN or TINVEST_TOKEN env or use -token-file",
+		errNoToken, p.name, strings.ToUpper(p.name))
+}
+
+func readToken(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read token file: %w", err)
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("%w in file %s", errNoToken, path)
+	}
+
+	return token, nil
+}
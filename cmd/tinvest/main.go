@@ -0,0 +1,188 @@
+// Command tinvest is command-line client of Tinkoff Invest OpenAPI.
+//
+// Usage:
+//
+//	tinvest [global flags] <command> [command flags]
+//
+// Token is read from -token-file, TINVEST_<PROFILE>_TOKEN or TINVEST_TOKEN env
+// or ~/.config/tinvest/<profile>.token file.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"time"
+
+	sdk "github.com/Tinkoff/invest-openapi-go-sdk"
+)
+
+var errUsage = errors.New("invalid usage")
+
+type (
+	app struct {
+		profile profile
+		token   string
+		client  *sdk.RestClient
+		out     *printer
+		account string
+		confirm bool
+	}
+
+	command struct {
+		usage string
+		run   func(ctx context.Context, a *app, args []string) error
+		// follow command runs until interrupt, timeout is applied only if it is set explicitly.
+		follow bool
+	}
+)
+
+// commands returns commands by name.
+func commands() map[string]command {
+	return map[string]command{
+		"accounts":    {usage: "list broker accounts", run: runAccounts},
+		"portfolio":   {usage: "show positions and currencies of the account", run: runPortfolio},
+		"orders":      {usage: "list|limit|market|cancel|cancel-all active orders", run: runOrders},
+		"operations":  {usage: "list operations for period", run: runOperations},
+		"instruments": {usage: "search|get instruments", run: runInstruments},
+		"candles":     {usage: "show historical candles", run: runCandles},
+		"orderbook":   {usage: "show orderbook", run: runOrderbook},
+		"stream":      {usage: "tail live candles, orderbooks and trade status", run: runStream, follow: true},
+	}
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "tinvest:", err)
+		if errors.Is(err, errUsage) {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var (
+		profileName = flag.String("profile", "sandbox", "profile: sandbox or production")
+		tokenFile   = flag.String("token-file", "", "file with token")
+		apiURL      = flag.String("url", "", "REST api url overriding url of the profile")
+		output      = flag.String("output", formatTable, "output format: table, json or csv")
+		account     = flag.String("account", sdk.DefaultAccount, "broker account id, default account if empty")
+		timeout     = flag.Duration("timeout", time.Minute, "timeout of the command")
+		confirm     = flag.Bool("yes", false, "confirm placing and cancelling orders in production profile")
+	)
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		return errUsage
+	}
+
+	cmd, ok := commands()[flag.Arg(0)]
+	if !ok {
+		usage()
+		return fmt.Errorf("%w: unknown command %q", errUsage, flag.Arg(0))
+	}
+
+	p, err := profileByName(*profileName)
+	if err != nil {
+		return err
+	}
+	if *apiURL != "" {
+		p.restURL = *apiURL
+	}
+
+	token, err := loadToken(p, *tokenFile)
+	if err != nil {
+		return err
+	}
+
+	out, err := newPrinter(*output, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	a := &app{
+		profile: p,
+		token:   token,
+		client:  sdk.NewRestClient(token, sdk.WithURL(p.restURL)),
+		out:     out,
+		account: *account,
+		confirm: *confirm,
+	}
+
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if !cmd.follow || isFlagSet("timeout") {
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+	}
+	defer cancel()
+
+	ctx, stop := withInterrupt(ctx)
+	defer stop()
+
+	return cmd.run(ctx, a, flag.Args()[1:])
+}
+
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+
+	return set
+}
+
+// withInterrupt returns ctx cancelled by interrupt signal.
+func withInterrupt(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel()
+	}
+}
+
+// checkConfirmed prevents accidental trading operations in production.
+func (a *app) checkConfirmed(action string) error {
+	if a.profile.sandbox || a.confirm {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s in production profile requires -yes flag", errUsage, action)
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: tinvest [global flags] <command> [command flags]\n\nCommands:\n")
+
+	cmds := commands()
+	names := make([]string, 0, len(cmds))
+	for name := range cmds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(out, "  %-12s %s\n", name, cmds[name].usage)
+	}
+
+	fmt.Fprintf(out, "\nGlobal flags:\n")
+	flag.PrintDefaults()
+}
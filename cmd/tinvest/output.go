@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Output formats.
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+// table is tabular view of command result, value is written as is in JSON format.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+type printer struct {
+	format string
+	w      io.Writer
+}
+
+func newPrinter(format string, w io.Writer) (*printer, error) {
+	switch format {
+	case formatTable, formatJSON, formatCSV:
+		return &printer{format: format, w: w}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q, use table, json or csv", format)
+	}
+}
+
+// print writes value in JSON format or its tables in table and CSV formats.
+func (p *printer) print(value interface{}, tables ...table) error {
+	if p.format == formatJSON {
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(value)
+	}
+
+	for i, t := range tables {
+		if i > 0 {
+			if _, err := fmt.Fprintln(p.w); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		if p.format == formatCSV {
+			err = p.csv(t)
+		} else {
+			err = p.table(t)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *printer) table(t table) error {
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+func (p *printer) csv(t table) error {
+	w := csv.NewWriter(p.w)
+	if err := w.Write(t.header); err != nil {
+		return err
+	}
+	if err := w.WriteAll(t.rows); err != nil {
+		return err
+	}
+
+	return w.Error()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func formatInt(v int) string {
+	return strconv.Itoa(v)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	sdk "github.com/Tinkoff/invest-openapi-go-sdk"
+)
+
+// maxStatusChanges is count of trade status changes shown by live view.
+const maxStatusChanges = 10
+
+// clearScreen moves cursor home and clears terminal.
+const clearScreen = "\033[H\033[2J"
+
+var errAmbiguousTicker = errors.New("ticker matches several instruments, use FIGI")
+
+type (
+	// instrumentLookup resolves stream arguments, implemented by sdk.RestClient.
+	instrumentLookup interface {
+		InstrumentByFIGI(ctx context.Context, figi string) (sdk.Instrument, error)
+		InstrumentByTicker(ctx context.Context, ticker string) ([]sdk.Instrument, error)
+	}
+
+	// streamRecord is line of NDJSON feed.
+	streamRecord struct {
+		Received time.Time   `json:"received"`
+		Ticker   string      `json:"ticker,omitempty"`
+		Event    interface{} `json:"event"`
+	}
+
+	// liveView keeps the latest market data for terminal rendering.
+	liveView struct {
+		rows    int
+		depth   int
+		tickers map[string]string // figi -> ticker
+
+		mu      sync.Mutex
+		candles map[string][]sdk.Candle
+		books   map[string]sdk.OrderBook
+		status  map[string]sdk.TradingStatus
+		changes []statusChange
+		errors  []string
+	}
+
+	statusChange struct {
+		time time.Time
+		figi string
+		from sdk.TradingStatus
+		to   sdk.TradingStatus
+	}
+)
+
+func runStream(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("stream")
+	interval := fs.String("candles", string(sdk.CandleInterval1Min), "candle interval, empty to skip candles")
+	depth := fs.Int("orderbook", 5, "orderbook depth, 0 to skip orderbook")
+	info := fs.Bool("info", true, "subscribe to instrument info")
+	ndjson := fs.Bool("ndjson", false, "write events as NDJSON to stdout instead of live view")
+	rows := fs.Int("rows", 10, "count of candles shown per instrument")
+	refresh := fs.Duration("refresh", time.Second, "live view refresh period")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("%w: tickers or FIGIs are required", errUsage)
+	}
+	if *rows < 1 {
+		return fmt.Errorf("%w: -rows must be positive, got %d", errUsage, *rows)
+	}
+	if *refresh <= 0 {
+		return fmt.Errorf("%w: -refresh must be positive, got %s", errUsage, *refresh)
+	}
+
+	tickers, err := resolveFIGIs(ctx, a.client, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	logger := log.New(os.Stderr, "[tinvest] ", log.LstdFlags)
+	client, err := sdk.NewStreamingClient(logger, a.token,
+		sdk.WithStreamingURL(a.profile.streamingURL),
+		sdk.WithStreamingLogger(sdk.NewPrintfLogger(logger, sdk.LogWarn)),
+	)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	view := &liveView{
+		rows:    *rows,
+		depth:   *depth,
+		tickers: tickers,
+		candles: make(map[string][]sdk.Candle),
+		books:   make(map[string]sdk.OrderBook),
+		status:  make(map[string]sdk.TradingStatus),
+	}
+
+	handler := view.update
+	if *ndjson {
+		handler = ndjsonWriter(os.Stdout, tickers)
+	} else {
+		go view.render(ctx, os.Stdout, *refresh)
+	}
+
+	if err := subscribeStream(ctx, client, tickers, sdk.CandleInterval(*interval), *depth, *info); err != nil {
+		return err
+	}
+
+	err = client.RunReadLoopContext(ctx, handler)
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	return err
+}
+
+// resolveFIGIs returns tickers of instruments by FIGI, argument which isn't FIGI is resolved as ticker.
+// Ticker must match single instrument, FIGI must be used for tickers traded on several exchanges.
+func resolveFIGIs(ctx context.Context, client instrumentLookup, ids []string) (map[string]string, error) {
+	tickers := make(map[string]string, len(ids))
+	for _, id := range ids {
+		if sdk.IsValidFIGI(id) {
+			instrument, err := client.InstrumentByFIGI(ctx, id)
+			if err != nil {
+				return nil, fmt.Errorf("resolve %s: %w", id, err)
+			}
+			tickers[instrument.FIGI] = instrument.Ticker
+			continue
+		}
+
+		instruments, err := client.InstrumentByTicker(ctx, strings.ToUpper(id))
+		if err != nil {
+			return nil, fmt.Errorf("resolve %s: %w", id, err)
+		}
+		switch len(instruments) {
+		case 0:
+			return nil, fmt.Errorf("resolve %s: %w", id, sdk.ErrNotFound)
+		case 1:
+			tickers[instruments[0].FIGI] = instruments[0].Ticker
+		default:
+			figis := make([]string, len(instruments))
+			for i, instrument := range instruments {
+				figis[i] = instrument.FIGI
+			}
+			sort.Strings(figis)
+
+			return nil, fmt.Errorf("resolve %s: %w: %s", id, errAmbiguousTicker, strings.Join(figis, ", "))
+		}
+	}
+
+	return tickers, nil
+}
+
+func subscribeStream(ctx context.Context, client *sdk.StreamingClient, tickers map[string]string, interval sdk.CandleInterval, depth int, info bool) error {
+	figis := make([]string, 0, len(tickers))
+	for figi := range tickers {
+		figis = append(figis, figi)
+	}
+	sort.Strings(figis)
+
+	if interval != "" {
+		if err := client.SubscribeCandleBatch(ctx, figis, interval); err != nil {
+			return err
+		}
+	}
+	if depth > 0 {
+		if err := client.SubscribeOrderbookBatch(ctx, figis, depth); err != nil {
+			return err
+		}
+	}
+	if info {
+		if err := client.SubscribeInstrumentInfoBatch(ctx, figis); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func ndjsonWriter(w io.Writer, tickers map[string]string) func(event interface{}) error {
+	enc := json.NewEncoder(w)
+
+	return func(event interface{}) error {
+		record := streamRecord{Received: time.Now(), Event: event}
+
+		switch e := event.(type) {
+		case sdk.CandleEvent:
+			record.Ticker = tickers[e.Candle.FIGI]
+		case sdk.OrderBookEvent:
+			record.Ticker = tickers[e.OrderBook.FIGI]
+		case sdk.InstrumentInfoEvent:
+			record.Ticker = tickers[e.Info.FIGI]
+		}
+
+		return enc.Encode(record)
+	}
+}
+
+func (v *liveView) update(event interface{}) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	switch e := event.(type) {
+	case sdk.CandleEvent:
+		v.addCandle(e.Candle)
+	case sdk.OrderBookEvent:
+		v.books[e.OrderBook.FIGI] = e.OrderBook
+	case sdk.InstrumentInfoEvent:
+		prev, known := v.status[e.Info.FIGI]
+		if known && prev != e.Info.TradeStatus {
+			v.changes = append(v.changes, statusChange{time: e.Time, figi: e.Info.FIGI, from: prev, to: e.Info.TradeStatus})
+			if len(v.changes) > maxStatusChanges {
+				v.changes = v.changes[1:]
+			}
+		}
+		v.status[e.Info.FIGI] = e.Info.TradeStatus
+	case sdk.ErrorEvent:
+		v.errors = append(v.errors, e.Error.RequestID+": "+e.Error.Error)
+		if len(v.errors) > maxStatusChanges {
+			v.errors = v.errors[1:]
+		}
+	}
+
+	return nil
+}
+
+// addCandle updates candle of the same time or appends new one keeping the last rows candles.
+func (v *liveView) addCandle(candle sdk.Candle) {
+	candles := v.candles[candle.FIGI]
+	if n := len(candles); n > 0 && candles[n-1].TS.Equal(candle.TS) {
+		candles[n-1] = candle
+		return
+	}
+
+	candles = append(candles, candle)
+	if len(candles) > v.rows {
+		candles = candles[len(candles)-v.rows:]
+	}
+	v.candles[candle.FIGI] = candles
+}
+
+func (v *liveView) render(ctx context.Context, w io.Writer, refresh time.Duration) {
+	ticker := time.NewTicker(refresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			var buf bytes.Buffer
+			buf.WriteString(clearScreen)
+			v.write(&buf)
+			if _, err := w.Write(buf.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (v *liveView) write(w io.Writer) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	figis := make([]string, 0, len(v.tickers))
+	for figi := range v.tickers {
+		figis = append(figis, figi)
+	}
+	sort.Strings(figis)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, figi := range figis {
+		fmt.Fprintf(tw, "%s (%s)  %s\n", v.tickers[figi], figi, v.status[figi])
+
+		if candles := v.candles[figi]; len(candles) > 0 {
+			fmt.Fprintln(tw, "TIME\tOPEN\tHIGH\tLOW\tCLOSE\tVOLUME")
+			for _, c := range candles {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", c.TS.Local().Format("15:04:05"),
+					formatFloat(c.OpenPrice), formatFloat(c.HighPrice), formatFloat(c.LowPrice), formatFloat(c.ClosePrice), formatFloat(c.Volume))
+			}
+		}
+
+		if book, ok := v.books[figi]; ok {
+			fmt.Fprintln(tw, "\tASK QTY\tPRICE\tBID QTY")
+			for i := minInt(len(book.Asks), v.depth) - 1; i >= 0; i-- {
+				fmt.Fprintf(tw, "\t%s\t%s\t\n", formatFloat(book.Asks[i][1]), formatFloat(book.Asks[i][0]))
+			}
+			for i := 0; i < minInt(len(book.Bids), v.depth); i++ {
+				fmt.Fprintf(tw, "\t\t%s\t%s\n", formatFloat(book.Bids[i][0]), formatFloat(book.Bids[i][1]))
+			}
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+
+	if len(v.changes) > 0 {
+		fmt.Fprintln(w, "Trade status changes:")
+		for _, c := range v.changes {
+			fmt.Fprintf(w, "  %s %s %s -> %s\n", c.time.Local().Format("15:04:05"), v.tickers[c.figi], c.from, c.to)
+		}
+	}
+
+	for _, e := range v.errors {
+		fmt.Fprintln(w, "Error:", e)
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
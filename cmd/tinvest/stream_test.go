@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	sdk "github.com/Tinkoff/invest-openapi-go-sdk"
+)
+
+type fakeLookup struct {
+	instruments []sdk.Instrument
+}
+
+func (l fakeLookup) InstrumentByFIGI(_ context.Context, figi string) (sdk.Instrument, error) {
+	for _, instrument := range l.instruments {
+		if instrument.FIGI == figi {
+			return instrument, nil
+		}
+	}
+
+	return sdk.Instrument{}, sdk.ErrNotFound
+}
+
+func (l fakeLookup) InstrumentByTicker(_ context.Context, ticker string) ([]sdk.Instrument, error) {
+	var found []sdk.Instrument
+	for _, instrument := range l.instruments {
+		if instrument.Ticker == ticker {
+			found = append(found, instrument)
+		}
+	}
+
+	return found, nil
+}
+
+func TestResolveFIGIs(t *testing.T) {
+	lookup := fakeLookup{instruments: []sdk.Instrument{
+		{FIGI: "BBG004730N88", Ticker: "SBER"},
+		{FIGI: "BBG000B9XRY4", Ticker: "AAPL"},
+		{FIGI: "BBG000BPH459", Ticker: "MSFT"},
+		{FIGI: "BBG00ZZZZZZ1", Ticker: "DUAL"},
+		{FIGI: "BBG00ZZZZZZ0", Ticker: "DUAL"},
+	}}
+
+	tests := []struct {
+		name    string
+		ids     []string
+		want    map[string]string
+		wantErr error
+		errText string
+	}{
+		{name: "figi", ids: []string{"BBG000B9XRY4"}, want: map[string]string{"BBG000B9XRY4": "AAPL"}},
+		{name: "ticker", ids: []string{"sber"}, want: map[string]string{"BBG004730N88": "SBER"}},
+		{
+			name: "mixed",
+			ids:  []string{"SBER", "BBG000BPH459"},
+			want: map[string]string{"BBG004730N88": "SBER", "BBG000BPH459": "MSFT"},
+		},
+		{name: "unknown ticker", ids: []string{"SBER", "NOPE"}, wantErr: sdk.ErrNotFound, errText: "resolve NOPE"},
+		{name: "unknown figi", ids: []string{"BBG000000000"}, wantErr: sdk.ErrNotFound, errText: "resolve BBG000000000"},
+		{name: "ambiguous ticker", ids: []string{"DUAL"}, wantErr: errAmbiguousTicker, errText: "BBG00ZZZZZZ0, BBG00ZZZZZZ1"},
+		// prefix alone doesn't make FIGI, such argument is resolved as ticker
+		{name: "short BBG ticker", ids: []string{"BBG"}, wantErr: sdk.ErrNotFound, errText: "resolve BBG"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveFIGIs(context.Background(), lookup, tt.ids)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("want %v, got %v", tt.wantErr, err)
+				}
+				if !strings.Contains(err.Error(), tt.errText) {
+					t.Errorf("error %q doesn't contain %q", err, tt.errText)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLiveViewAddCandle(t *testing.T) {
+	start := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+	candle := func(figi string, minute int, closePrice float64) sdk.Candle {
+		return sdk.Candle{FIGI: figi, TS: start.Add(time.Duration(minute) * time.Minute), ClosePrice: closePrice}
+	}
+
+	tests := []struct {
+		name   string
+		rows   int
+		add    []sdk.Candle
+		figi   string
+		closes []float64
+	}{
+		{
+			name:   "append",
+			rows:   3,
+			add:    []sdk.Candle{candle("A", 0, 1), candle("A", 1, 2)},
+			figi:   "A",
+			closes: []float64{1, 2},
+		},
+		{
+			name:   "update the last candle",
+			rows:   3,
+			add:    []sdk.Candle{candle("A", 0, 1), candle("A", 1, 2), candle("A", 1, 2.5)},
+			figi:   "A",
+			closes: []float64{1, 2.5},
+		},
+		{
+			name:   "trim to rows",
+			rows:   2,
+			add:    []sdk.Candle{candle("A", 0, 1), candle("A", 1, 2), candle("A", 2, 3), candle("A", 3, 4)},
+			figi:   "A",
+			closes: []float64{3, 4},
+		},
+		{
+			name:   "single row",
+			rows:   1,
+			add:    []sdk.Candle{candle("A", 0, 1), candle("A", 1, 2), candle("A", 1, 2.5)},
+			figi:   "A",
+			closes: []float64{2.5},
+		},
+		{
+			name:   "instruments are kept apart",
+			rows:   2,
+			add:    []sdk.Candle{candle("A", 0, 1), candle("B", 0, 10), candle("A", 1, 2), candle("B", 0, 11)},
+			figi:   "B",
+			closes: []float64{11},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			view := &liveView{rows: tt.rows, candles: make(map[string][]sdk.Candle)}
+			for _, c := range tt.add {
+				view.addCandle(c)
+			}
+
+			candles := view.candles[tt.figi]
+			closes := make([]float64, len(candles))
+			for i, c := range candles {
+				closes[i] = c.ClosePrice
+			}
+			if !reflect.DeepEqual(closes, tt.closes) {
+				t.Errorf("close prices %v, want %v", closes, tt.closes)
+			}
+		})
+	}
+}
+
+func TestRunStreamBadFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "no instruments", args: nil},
+		{name: "unknown flag", args: []string{"-bogus", "SBER"}},
+		{name: "zero rows", args: []string{"-rows", "0", "SBER"}},
+		{name: "negative rows", args: []string{"-rows", "-3", "SBER"}},
+		{name: "zero refresh", args: []string{"-refresh", "0s", "SBER"}},
+		{name: "malformed depth", args: []string{"-orderbook", "deep", "SBER"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			// flags are checked before client is used, so app without client is enough
+			err := runStream(context.Background(), &app{}, tt.args)
+			if !errors.Is(err, errUsage) {
+				t.Errorf("want errUsage, got %v", err)
+			}
+		})
+	}
+}
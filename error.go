@@ -1,6 +1,21 @@
 package sdk
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Errors of api responses, use errors.Is to check them.
+var (
+	ErrRateLimited        = errors.New("rate limited")
+	ErrNotEnoughBalance   = errors.New("not enough balance")
+	ErrInstrumentNotFound = errors.New("instrument not found")
+)
 
 // TradingError contains error info from tinkoff invest.
 type TradingError struct {
@@ -23,10 +38,108 @@ func (t TradingError) Error() string {
 
 // NotEnoughBalance for check error.
 func (t TradingError) NotEnoughBalance() bool {
-	return t.Payload.Code == "NOT_ENOUGH_BALANCE"
+	return t.IsCode(ErrorCodeNotEnoughBalance)
 }
 
 // InvalidTokenSpace for check error.
 func (t TradingError) InvalidTokenSpace() bool {
-	return t.Payload.Message == "Invalid token scopes"
+	return t.Payload.Message == "Invalid token scopes" || t.IsCode(ErrorCodeInvalidTokenScopes)
+}
+
+// InstrumentNotFound for check error.
+func (t TradingError) InstrumentNotFound() bool {
+	return t.IsCode(ErrorCodeInstrumentNotFound) || strings.Contains(strings.ToLower(t.Payload.Message), "instrument not found")
+}
+
+// APIError is returned by default provider for response with status other than 200.
+// Err is TradingError if body is decoded, ErrNotFound for 404 or nil if body isn't api error json.
+type APIError struct {
+	StatusCode int
+	Method     string
+	Path       string
+	TrackingID string
+	Body       string // truncated raw body
+	Err        error
+}
+
+// Error for implements error.
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode))
+
+	switch {
+	case e.Err != nil:
+		return msg + ": " + e.Err.Error()
+	case e.Body != "":
+		return msg + ": " + e.Body
+	default:
+		return msg
+	}
+}
+
+// Unwrap returns TradingError or ErrNotFound.
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Is maps status code and trading error code to ErrUnauthorized, ErrForbidden, ErrRateLimited,
+// ErrNotEnoughBalance and ErrInstrumentNotFound.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		var t TradingError
+		return e.StatusCode == http.StatusTooManyRequests || (errors.As(e.Err, &t) && t.IsCode(ErrorCodeTooManyRequests))
+	case ErrNotEnoughBalance:
+		var t TradingError
+		return errors.As(e.Err, &t) && t.NotEnoughBalance()
+	case ErrInstrumentNotFound:
+		var t TradingError
+		return errors.As(e.Err, &t) && t.InstrumentNotFound()
+	default:
+		return false
+	}
+}
+
+// Retryable reports whether the same request may succeed later. Rate limited requests are retryable,
+// server errors are retryable for GET requests only, because POST may be executed before the error.
+func (e *APIError) Retryable() bool {
+	switch {
+	case e.StatusCode == http.StatusTooManyRequests:
+		return true
+	case e.StatusCode >= http.StatusInternalServerError:
+		return e.Method == http.MethodGet
+	default:
+		return false
+	}
+}
+
+// IsRetryable reports whether request failed by err may be retried: APIError.Retryable or network timeout
+// including timeout of http client. Cancelled requests aren't retryable, caller should check its context
+// before retry, because request failed by context deadline is network timeout too.
+// Timeout doesn't mean that request isn't executed, so it should be used for idempotent GET requests only.
+func IsRetryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	// http client timeout wraps context.DeadlineExceeded too
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Timeout()
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
 }
@@ -0,0 +1,159 @@
+package sdk
+
+// ErrorCode is TradingError.Payload.Code returned by OpenAPI.
+type ErrorCode string
+
+// Error codes of OpenAPI responses returned in payload.code of Error schema, see
+// https://tinkoffcreditsystems.github.io/invest-openapi/swagger-ui/. Other codes may be checked by IsCode.
+const (
+	ErrorCodeValidation            ErrorCode = "VALIDATION_ERROR"
+	ErrorCodeInternal              ErrorCode = "INTERNAL_ERROR"
+	ErrorCodeTooManyRequests       ErrorCode = "TOO_MANY_REQUESTS"
+	ErrorCodeInvalidTokenScopes    ErrorCode = "INVALID_TOKEN_SCOPES"
+	ErrorCodeNotEnoughBalance      ErrorCode = "NOT_ENOUGH_BALANCE"
+	ErrorCodeBrokerAccountNotFound ErrorCode = "BROKER_ACCOUNT_NOT_FOUND"
+	ErrorCodeInstrumentNotFound    ErrorCode = "INSTRUMENT_NOT_FOUND"
+	ErrorCodeInstrumentNotTraded   ErrorCode = "INSTRUMENT_NOT_AVAILABLE_FOR_TRADING"
+	ErrorCodeMarketClosed          ErrorCode = "MARKET_CLOSED"
+
+	// Order rejections.
+	ErrorCodeOrderError           ErrorCode = "ORDER_ERROR"
+	ErrorCodeOrderRejected        ErrorCode = "ORDER_REJECTED"
+	ErrorCodeOrderNotFound        ErrorCode = "ORDER_NOT_FOUND"
+	ErrorCodeOrderCancelError     ErrorCode = "ORDER_CANCEL_ERROR"
+	ErrorCodeMarketOrderForbidden ErrorCode = "MARKET_ORDER_FORBIDDEN"
+	ErrorCodeShortForbidden       ErrorCode = "SHORT_FORBIDDEN"
+
+	// Price and lot errors.
+	ErrorCodeInvalidPrice       ErrorCode = "INVALID_PRICE"
+	ErrorCodePriceIncrement     ErrorCode = "PRICE_NOT_MULTIPLE_OF_MIN_PRICE_INCREMENT"
+	ErrorCodePriceOutOfLimits   ErrorCode = "PRICE_OUT_OF_LIMITS"
+	ErrorCodeInvalidLots        ErrorCode = "INVALID_LOTS"
+	ErrorCodeLotsExceedPosition ErrorCode = "LOTS_EXCEED_POSITION"
+
+	// Sandbox only.
+	ErrorCodeSandboxNotRegistered   ErrorCode = "SANDBOX_ACCOUNT_NOT_REGISTERED"
+	ErrorCodeSandboxInvalidCurrency ErrorCode = "SANDBOX_INVALID_CURRENCY"
+	ErrorCodeSandboxInvalidPosition ErrorCode = "SANDBOX_INVALID_POSITION"
+)
+
+// Code returns typed error code.
+func (t TradingError) Code() ErrorCode {
+	return ErrorCode(t.Payload.Code)
+}
+
+// IsCode for check error.
+func (t TradingError) IsCode(codes ...ErrorCode) bool {
+	for _, code := range codes {
+		if t.Code() == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OrderRejected for check error.
+func (t TradingError) OrderRejected() bool {
+	return t.IsCode(ErrorCodeOrderError, ErrorCodeOrderRejected, ErrorCodeMarketOrderForbidden, ErrorCodeShortForbidden)
+}
+
+// MarketClosed for check error.
+func (t TradingError) MarketClosed() bool {
+	return t.IsCode(ErrorCodeMarketClosed, ErrorCodeInstrumentNotTraded)
+}
+
+// InvalidPrice for check error.
+func (t TradingError) InvalidPrice() bool {
+	return t.IsCode(ErrorCodeInvalidPrice, ErrorCodePriceIncrement, ErrorCodePriceOutOfLimits)
+}
+
+// InvalidLots for check error.
+func (t TradingError) InvalidLots() bool {
+	return t.IsCode(ErrorCodeInvalidLots, ErrorCodeLotsExceedPosition)
+}
+
+// AccountNotFound for check error.
+func (t TradingError) AccountNotFound() bool {
+	return t.IsCode(ErrorCodeBrokerAccountNotFound, ErrorCodeSandboxNotRegistered)
+}
+
+// OrderNotFound for check error.
+func (t TradingError) OrderNotFound() bool {
+	return t.IsCode(ErrorCodeOrderNotFound)
+}
+
+// SandboxError for check error.
+func (t TradingError) SandboxError() bool {
+	return t.IsCode(ErrorCodeSandboxNotRegistered, ErrorCodeSandboxInvalidCurrency, ErrorCodeSandboxInvalidPosition)
+}
+
+// withHint returns error with user-facing hint by code, hint which is already set is kept.
+func (t TradingError) withHint() TradingError {
+	if t.Hint != "" {
+		return t
+	}
+
+	if t.InvalidTokenSpace() {
+		t.Hint = "Do you use sandbox token in production environment or vise verse?"
+		return t
+	}
+
+	t.Hint = errorHint(t.Code())
+
+	return t
+}
+
+// errorHint returns user-facing hint of error code or empty string for unknown code.
+func errorHint(code ErrorCode) string {
+	switch code {
+	case ErrorCodeValidation:
+		return "Check request parameters against API documentation."
+	case ErrorCodeInternal:
+		return "Server error, retry the request later."
+	case ErrorCodeTooManyRequests:
+		return "Request limit is exceeded, slow down and retry later."
+	case ErrorCodeInvalidTokenScopes:
+		return "Do you use sandbox token in production environment or vise verse?"
+	case ErrorCodeNotEnoughBalance:
+		return "Top up the account or reduce order lots."
+	case ErrorCodeBrokerAccountNotFound:
+		return "Check broker account id, use Accounts to list available ones."
+	case ErrorCodeInstrumentNotFound:
+		return "Check FIGI, use InstrumentByTicker to find it."
+	case ErrorCodeInstrumentNotTraded:
+		return "Instrument isn't available for trading now or for your account."
+	case ErrorCodeMarketClosed:
+		return "Exchange is closed, place the order during trading session."
+	case ErrorCodeOrderError:
+		return "Order is rejected by broker, see message for details."
+	case ErrorCodeOrderRejected:
+		return "Order is rejected by exchange, see message for details."
+	case ErrorCodeOrderNotFound:
+		return "Order is already executed or cancelled, refresh active orders."
+	case ErrorCodeOrderCancelError:
+		return "Order can't be cancelled now, it may be already executed."
+	case ErrorCodeMarketOrderForbidden:
+		return "Market orders aren't allowed for the instrument now, use limit order."
+	case ErrorCodeShortForbidden:
+		return "Short selling isn't allowed for the instrument or account."
+	case ErrorCodeInvalidPrice:
+		return "Check order price."
+	case ErrorCodePriceIncrement:
+		return "Round price to instrument MinPriceIncrement, see InstrumentCatalog.RoundPrice."
+	case ErrorCodePriceOutOfLimits:
+		return "Price is out of exchange limits, check orderbook limit prices."
+	case ErrorCodeInvalidLots:
+		return "Lots must be positive integer count of instrument lots."
+	case ErrorCodeLotsExceedPosition:
+		return "Lots exceed current position, check Portfolio."
+	case ErrorCodeSandboxNotRegistered:
+		return "Call Register to create sandbox account first."
+	case ErrorCodeSandboxInvalidCurrency:
+		return "Use one of supported currencies for SetCurrencyBalance."
+	case ErrorCodeSandboxInvalidPosition:
+		return "Check FIGI and balance for SetPositionsBalance."
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,135 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "rate limited GET", err: &APIError{StatusCode: http.StatusTooManyRequests, Method: http.MethodGet}, want: true},
+		{name: "rate limited POST", err: &APIError{StatusCode: http.StatusTooManyRequests, Method: http.MethodPost}, want: true},
+		{name: "server error GET", err: &APIError{StatusCode: http.StatusBadGateway, Method: http.MethodGet}, want: true},
+		{name: "server error POST", err: &APIError{StatusCode: http.StatusInternalServerError, Method: http.MethodPost}, want: false},
+		{name: "bad request", err: &APIError{StatusCode: http.StatusBadRequest, Method: http.MethodGet}, want: false},
+		{name: "wrapped API error", err: fmt.Errorf("provider get: %w", &APIError{StatusCode: http.StatusServiceUnavailable, Method: http.MethodGet}), want: true},
+		{name: "network timeout", err: fmt.Errorf("provider do: %w", timeoutError{}), want: true},
+		{name: "canceled", err: fmt.Errorf("provider do: %w", context.Canceled), want: false},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, want: false},
+		{name: "other", err: errors.New("decode json"), want: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Fatalf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsRetryableClientTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	client := &http.Client{Timeout: 20 * time.Millisecond}
+	resp, err := client.Get(srv.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("want timeout error")
+	}
+
+	if !IsRetryable(fmt.Errorf("provider do: %w", err)) {
+		t.Fatalf("client timeout %v isn't retryable", err)
+	}
+}
+
+func TestTradingErrorCodes(t *testing.T) {
+	tests := []struct {
+		code      ErrorCode
+		predicate func(TradingError) bool
+	}{
+		{code: ErrorCodeNotEnoughBalance, predicate: TradingError.NotEnoughBalance},
+		{code: ErrorCodeInvalidTokenScopes, predicate: TradingError.InvalidTokenSpace},
+		{code: ErrorCodeInstrumentNotFound, predicate: TradingError.InstrumentNotFound},
+		{code: ErrorCodeOrderError, predicate: TradingError.OrderRejected},
+		{code: ErrorCodeOrderRejected, predicate: TradingError.OrderRejected},
+		{code: ErrorCodeMarketOrderForbidden, predicate: TradingError.OrderRejected},
+		{code: ErrorCodeShortForbidden, predicate: TradingError.OrderRejected},
+		{code: ErrorCodeMarketClosed, predicate: TradingError.MarketClosed},
+		{code: ErrorCodeInstrumentNotTraded, predicate: TradingError.MarketClosed},
+		{code: ErrorCodeInvalidPrice, predicate: TradingError.InvalidPrice},
+		{code: ErrorCodePriceIncrement, predicate: TradingError.InvalidPrice},
+		{code: ErrorCodePriceOutOfLimits, predicate: TradingError.InvalidPrice},
+		{code: ErrorCodeInvalidLots, predicate: TradingError.InvalidLots},
+		{code: ErrorCodeLotsExceedPosition, predicate: TradingError.InvalidLots},
+		{code: ErrorCodeBrokerAccountNotFound, predicate: TradingError.AccountNotFound},
+		{code: ErrorCodeSandboxNotRegistered, predicate: TradingError.AccountNotFound},
+		{code: ErrorCodeOrderNotFound, predicate: TradingError.OrderNotFound},
+		{code: ErrorCodeSandboxNotRegistered, predicate: TradingError.SandboxError},
+		{code: ErrorCodeSandboxInvalidCurrency, predicate: TradingError.SandboxError},
+		{code: ErrorCodeSandboxInvalidPosition, predicate: TradingError.SandboxError},
+	}
+
+	for _, tt := range tests {
+		var tradingErr TradingError
+		tradingErr.Payload.Code = string(tt.code)
+		if !tt.predicate(tradingErr) {
+			t.Errorf("predicate doesn't match %s", tt.code)
+		}
+		if hint := tradingErr.withHint().Hint; hint == "" {
+			t.Errorf("%s has no hint", tt.code)
+		}
+
+		var other TradingError
+		other.Payload.Code = string(ErrorCodeValidation)
+		if tt.predicate(other) {
+			t.Errorf("predicate of %s matches %s", tt.code, ErrorCodeValidation)
+		}
+	}
+
+	var unknown TradingError
+	unknown.Payload.Code = "SOMETHING_NEW"
+	if hint := unknown.withHint().Hint; hint != "" {
+		t.Errorf("unknown code has hint %q", hint)
+	}
+
+	unknown.Hint = "custom"
+	if hint := unknown.withHint().Hint; hint != "custom" {
+		t.Errorf("hint which is set is replaced by %q", hint)
+	}
+}
+
+func TestAPIErrorRateLimitedCode(t *testing.T) {
+	var tradingErr TradingError
+	tradingErr.Payload.Code = string(ErrorCodeTooManyRequests)
+
+	err := &APIError{StatusCode: http.StatusBadRequest, Method: http.MethodGet, Err: tradingErr}
+	if !errors.Is(err, ErrRateLimited) {
+		t.Error("TOO_MANY_REQUESTS code isn't ErrRateLimited")
+	}
+}
@@ -2,9 +2,9 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"log"
-	"math/rand"
 	"os"
 	"time"
 
@@ -15,7 +15,6 @@ var token = flag.String("token", "", "your token")
 var isSandbox = flag.Bool("is_sandbox", true, "is sandbox env")
 
 func main() {
-	rand.Seed(time.Now().UnixNano()) // инициируем Seed рандома для функции requestID
 	flag.Parse()
 
 	if *isSandbox {
@@ -30,7 +29,10 @@ func main() {
 func stream() {
 	logger := log.New(os.Stdout, "[invest-openapi-go-sdk]", log.LstdFlags)
 
-	client, err := sdk.NewStreamingClient(logger, *token)
+	// Пинг раз в 54 секунды, соединение считается потерянным, если понг не пришел за 60 секунд
+	client, err := sdk.NewStreamingClient(logger, *token,
+		sdk.WithStreamingKeepalive(sdk.DefaultPingPeriod, sdk.DefaultPongWait),
+	)
 	if err != nil {
 		log.Fatalln(err)
 	}
@@ -50,41 +52,45 @@ func stream() {
 	}()
 
 	log.Println("Подписка на получение событий по инструменту BBG005DXJS36 (TCS)")
-	err = client.SubscribeInstrumentInfo("BBG005DXJS36", requestID())
+	err = client.SubscribeInstrumentInfo("BBG005DXJS36", "")
 	if err != nil {
 		log.Fatalln(err)
 	}
 
 	log.Println("Подписка на получение свечей по инструменту BBG005DXJS36 (TCS)")
-	err = client.SubscribeCandle("BBG005DXJS36", sdk.CandleInterval5Min, requestID())
+	err = client.SubscribeCandle("BBG005DXJS36", sdk.CandleInterval5Min, "")
 	if err != nil {
 		log.Fatalln(err)
 	}
 
 	log.Println("Подписка на получения стакана по инструменту BBG005DXJS36 (TCS)")
-	err = client.SubscribeOrderbook("BBG005DXJS36", 10, requestID())
+	err = client.SubscribeOrderbook("BBG005DXJS36", 10, "")
 	if err != nil {
 		log.Fatalln(err)
 	}
 
+	// Пустой requestID заменяется уникальным сгенерированным клиентом.
+	// Состояние подписок (Pending, Active, Failed) доступно через Subscriptions и SubscriptionByRequestID
+	log.Printf("%+v\n", client.Subscriptions())
+
 	// Приложение завершится через 10секунд.
 	// Hint: В боевом приложении лучше обрабатывать сигналы завершения и работать в бесконечном цикле
 	time.Sleep(10 * time.Second)
 
 	log.Println("Отписка от получения событий по инструменту BBG005DXJS36 (TCS)")
-	err = client.UnsubscribeInstrumentInfo("BBG005DXJS36", requestID())
+	err = client.UnsubscribeInstrumentInfo("BBG005DXJS36", "")
 	if err != nil {
 		log.Fatalln(err)
 	}
 
 	log.Println("Отписка от получения свечей по инструменту BBG005DXJS36 (TCS)")
-	err = client.UnsubscribeCandle("BBG005DXJS36", sdk.CandleInterval5Min, requestID())
+	err = client.UnsubscribeCandle("BBG005DXJS36", sdk.CandleInterval5Min, "")
 	if err != nil {
 		log.Fatalln(err)
 	}
 
 	log.Println("Отписка от получения стакана по инструменту BBG005DXJS36 (TCS)")
-	err = client.UnsubscribeOrderbook("BBG005DXJS36", 10, requestID())
+	err = client.UnsubscribeOrderbook("BBG005DXJS36", 10, "")
 	if err != nil {
 		log.Fatalln(err)
 	}
@@ -376,28 +382,21 @@ func rest() {
 	}
 }
 
-var letterRunes = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
-
-// Генерируем уникальный ID для запроса
-func requestID() string {
-	b := make([]rune, 12)
-	for i := range b {
-		b[i] = letterRunes[rand.Intn(len(letterRunes))]
-	}
-
-	return string(b)
-}
-
 func errorHandle(err error) error {
 	if err == nil {
 		return nil
 	}
 
-	if tradingErr, ok := err.(sdk.TradingError); ok {
-		if tradingErr.InvalidTokenSpace() {
-			tradingErr.Hint = "Do you use sandbox token in production environment or vise verse?"
-			return tradingErr
-		}
+	// Hint is set by sdk for known error codes.
+	var tradingErr sdk.TradingError
+	if errors.As(err, &tradingErr) && tradingErr.Hint != "" {
+		log.Printf("Hint: %s", tradingErr.Hint)
+	}
+
+	var apiErr *sdk.APIError
+	if errors.As(err, &apiErr) {
+		log.Printf("Request %s %s failed with status %d, tracking id %s, retryable %t",
+			apiErr.Method, apiErr.Path, apiErr.StatusCode, apiErr.TrackingID, apiErr.Retryable())
 	}
 
 	return err
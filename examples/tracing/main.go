@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	sdk "github.com/Tinkoff/invest-openapi-go-sdk"
+)
+
+// logTracer is example of Tracer adapter. Adapter of real tracing system starts span in StartRequest,
+// puts it into returned context and sets span attributes and status in End.
+type logTracer struct {
+	logger *log.Logger
+}
+
+type requestSpan struct {
+	logger *log.Logger
+	info   sdk.RequestInfo
+	start  time.Time
+}
+
+type messageSpan struct {
+	logger *log.Logger
+	info   sdk.MessageInfo
+	start  time.Time
+}
+
+func (t logTracer) StartRequest(ctx context.Context, info sdk.RequestInfo) (context.Context, sdk.RequestSpan) {
+	return ctx, &requestSpan{logger: t.logger, info: info, start: time.Now()}
+}
+
+func (t logTracer) StartMessage(ctx context.Context, info sdk.MessageInfo) (context.Context, sdk.MessageSpan) {
+	return ctx, &messageSpan{logger: t.logger, info: info, start: time.Now()}
+}
+
+func (s *requestSpan) End(result sdk.RequestResult) {
+	s.logger.Printf("span %s %s %s account=%q figi=%q status=%d tracking_id=%s duration=%s err=%v",
+		s.info.Endpoint, s.info.Method, s.info.Path, s.info.AccountID, s.info.FIGI,
+		result.StatusCode, result.TrackingID, time.Since(s.start), result.Err)
+}
+
+func (s *messageSpan) End(err error) {
+	s.logger.Printf("span %s %s subscription=%s request_id=%s duration=%s err=%v",
+		s.info.Direction, s.info.Event, s.info.Subscription, s.info.RequestID, time.Since(s.start), err)
+}
+
+func main() {
+	token := flag.String("token", "", "your token")
+	flag.Parse()
+
+	logger := log.New(os.Stdout, "[tracing]", log.LstdFlags)
+	tracer := logTracer{logger: logger}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	client := sdk.NewSandboxRestClient(*token, sdk.WithTracer(tracer))
+
+	if _, err := client.Register(ctx, sdk.AccountTinkoff); err != nil {
+		logger.Fatalln(err)
+	}
+	if _, err := client.Orderbook(ctx, 5, "BBG005DXJS36"); err != nil {
+		logger.Fatalln(err)
+	}
+
+	stream, err := sdk.NewStreamingClient(logger, *token, sdk.WithStreamingTracer(tracer))
+	if err != nil {
+		logger.Fatalln(err)
+	}
+	defer stream.Close()
+
+	go func() {
+		if err := stream.SubscribeCandleContext(ctx, "BBG005DXJS36", sdk.CandleInterval1Min, ""); err != nil {
+			logger.Println(err)
+		}
+	}()
+
+	err = stream.RunReadLoopContext(ctx, func(event interface{}) error {
+		return nil
+	})
+	logger.Println(err)
+}
@@ -0,0 +1,286 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	sdk "github.com/Tinkoff/invest-openapi-go-sdk"
+)
+
+// csvRow reads typed values of record by column name, the first parse error is kept in err.
+type csvRow struct {
+	columns map[string]int
+	record  []string
+	err     error
+}
+
+// WriteCandlesCSV writes candles with header line to w.
+func WriteCandlesCSV(w io.Writer, candles []sdk.Candle, opts Options) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header(CandleColumns(), opts)); err != nil {
+		return err
+	}
+
+	for _, c := range candles {
+		record := []string{
+			c.FIGI,
+			string(c.Interval),
+			formatTime(opts.in(c.TS)),
+			formatFloat(c.OpenPrice),
+			formatFloat(c.HighPrice),
+			formatFloat(c.LowPrice),
+			formatFloat(c.ClosePrice),
+			formatFloat(c.Volume),
+		}
+		if err := cw.Write(withInstrument(record, c.FIGI, opts)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// ReadCandlesCSV reads candles written by WriteCandlesCSV, columns are matched by header names.
+func ReadCandlesCSV(r io.Reader, opts Options) ([]sdk.Candle, error) {
+	var candles []sdk.Candle
+	err := readCSV(r, CandleColumns(), func(row *csvRow) {
+		candles = append(candles, sdk.Candle{
+			FIGI:       row.string("figi"),
+			Interval:   sdk.CandleInterval(row.string("interval")),
+			TS:         opts.in(row.time("time")),
+			OpenPrice:  row.float("open"),
+			HighPrice:  row.float("high"),
+			LowPrice:   row.float("low"),
+			ClosePrice: row.float("close"),
+			Volume:     row.float("volume"),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return candles, nil
+}
+
+// WriteOperationsCSV writes operations with header line to w.
+func WriteOperationsCSV(w io.Writer, operations []sdk.Operation, opts Options) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header(OperationColumns(), opts)); err != nil {
+		return err
+	}
+
+	for _, op := range operations {
+		trades, err := marshalTrades(op.Trades)
+		if err != nil {
+			return fmt.Errorf("operation %s: %w", op.ID, err)
+		}
+
+		record := []string{
+			op.ID,
+			string(op.Status),
+			op.FIGI,
+			string(op.InstrumentType),
+			string(op.OperationType),
+			formatTime(opts.in(op.DateTime)),
+			string(op.Currency),
+			formatFloat(op.Payment),
+			formatFloat(op.Price),
+			strconv.Itoa(op.Quantity),
+			strconv.Itoa(op.QuantityExecuted),
+			formatFloat(op.Commission.Value),
+			string(op.Commission.Currency),
+			strconv.FormatBool(op.IsMarginCall),
+			trades,
+		}
+		if err := cw.Write(withInstrument(record, op.FIGI, opts)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// ReadOperationsCSV reads operations written by WriteOperationsCSV, columns are matched by header names.
+func ReadOperationsCSV(r io.Reader, opts Options) ([]sdk.Operation, error) {
+	var operations []sdk.Operation
+	err := readCSV(r, OperationColumns(), func(row *csvRow) {
+		op := sdk.Operation{
+			ID:               row.string("id"),
+			Status:           sdk.OperationStatus(row.string("status")),
+			FIGI:             row.string("figi"),
+			InstrumentType:   sdk.InstrumentType(row.string("instrument_type")),
+			OperationType:    sdk.OperationType(row.string("operation_type")),
+			DateTime:         opts.in(row.time("date")),
+			Currency:         sdk.Currency(row.string("currency")),
+			Payment:          row.float("payment"),
+			Price:            row.float("price"),
+			Quantity:         row.int("quantity"),
+			QuantityExecuted: row.int("quantity_executed"),
+			Commission: sdk.MoneyAmount{
+				Value:    row.float("commission"),
+				Currency: sdk.Currency(row.string("commission_currency")),
+			},
+			IsMarginCall: row.bool("is_margin_call"),
+		}
+		op.Trades = row.trades("trades", opts)
+		operations = append(operations, op)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return operations, nil
+}
+
+// readCSV checks header has required columns and calls fn for every record.
+func readCSV(r io.Reader, required []string, fn func(row *csvRow)) error {
+	cr := csv.NewReader(r)
+	head, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("read header: %w", err)
+	}
+
+	columns := make(map[string]int, len(head))
+	for i, name := range head {
+		columns[name] = i
+	}
+	for _, name := range required {
+		if _, ok := columns[name]; !ok {
+			return missingColumn(name)
+		}
+	}
+
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		row := &csvRow{columns: columns, record: record}
+		fn(row)
+		if row.err != nil {
+			return fmt.Errorf("line %d: %w", line, row.err)
+		}
+	}
+}
+
+func (r *csvRow) string(name string) string {
+	return r.record[r.columns[name]]
+}
+
+func (r *csvRow) float(name string) float64 {
+	v, err := strconv.ParseFloat(r.string(name), 64)
+	r.fail(name, err)
+
+	return v
+}
+
+func (r *csvRow) int(name string) int {
+	v, err := strconv.Atoi(r.string(name))
+	r.fail(name, err)
+
+	return v
+}
+
+func (r *csvRow) bool(name string) bool {
+	v, err := strconv.ParseBool(r.string(name))
+	r.fail(name, err)
+
+	return v
+}
+
+func (r *csvRow) time(name string) time.Time {
+	v, err := time.Parse(time.RFC3339Nano, r.string(name))
+	r.fail(name, err)
+
+	return v
+}
+
+func (r *csvRow) trades(name string, opts Options) []sdk.Trade {
+	trades, err := unmarshalTrades(r.string(name), opts)
+	r.fail(name, err)
+
+	return trades
+}
+
+func (r *csvRow) fail(name string, err error) {
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("column %q: %w", name, err)
+	}
+}
+
+func header(columns []string, opts Options) []string {
+	if opts.Instruments == nil {
+		return columns
+	}
+
+	return append(append([]string{}, columns...), InstrumentColumns()...)
+}
+
+// withInstrument appends instrument metadata columns if instruments resolver is set.
+func withInstrument(record []string, figi string, opts Options) []string {
+	if opts.Instruments == nil {
+		return record
+	}
+
+	instrument, ok := opts.instrument(figi)
+	if !ok {
+		return append(record, make([]string, len(InstrumentColumns()))...)
+	}
+
+	return append(record,
+		instrument.Ticker,
+		instrument.ISIN,
+		instrument.Name,
+		string(instrument.Currency),
+		strconv.Itoa(instrument.Lot),
+		formatFloat(instrument.MinPriceIncrement),
+	)
+}
+
+// marshalTrades returns JSON array of trades, empty string if there are no trades.
+func marshalTrades(trades []sdk.Trade) (string, error) {
+	if len(trades) == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal(trades)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func unmarshalTrades(data string, opts Options) ([]sdk.Trade, error) {
+	if data == "" {
+		return nil, nil
+	}
+
+	var trades []sdk.Trade
+	if err := json.Unmarshal([]byte(data), &trades); err != nil {
+		return nil, err
+	}
+	for i := range trades {
+		trades[i].DateTime = opts.in(trades[i].DateTime)
+	}
+
+	return trades, nil
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
@@ -0,0 +1,68 @@
+// Package export writes candles and operations to CSV and Parquet files and reads them back,
+// e.g. to keep market history for backtesting.
+//
+// Columns of the files are listed in CandleColumns, OperationColumns and InstrumentColumns.
+// Instrument columns are filled from Options.Instruments by FIGI, they are ignored by readers.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	sdk "github.com/Tinkoff/invest-openapi-go-sdk"
+)
+
+// ErrMissingColumn returned by readers when required column is absent in file.
+var ErrMissingColumn = errors.New("missing column")
+
+// CandleColumns returns columns of candles file.
+func CandleColumns() []string {
+	return []string{"figi", "interval", "time", "open", "high", "low", "close", "volume"}
+}
+
+// OperationColumns returns columns of operations file, trades are stored as JSON array.
+func OperationColumns() []string {
+	return []string{
+		"id", "status", "figi", "instrument_type", "operation_type", "date", "currency",
+		"payment", "price", "quantity", "quantity_executed", "commission", "commission_currency",
+		"is_margin_call", "trades",
+	}
+}
+
+// InstrumentColumns returns optional instrument metadata columns following candle or operation columns.
+func InstrumentColumns() []string {
+	return []string{"ticker", "isin", "name", "instrument_currency", "lot", "min_price_increment"}
+}
+
+// Options of export and import.
+type Options struct {
+	// Location of written CSV times and of times returned by readers.
+	// Times are left as is in CSV and returned in UTC from Parquet if it is nil.
+	// Parquet always stores UTC timestamps.
+	Location *time.Location
+
+	// Instruments resolves instrument metadata columns. CSV files have no metadata columns
+	// if it is nil, Parquet files have empty ones.
+	Instruments sdk.InstrumentResolver
+}
+
+func (o Options) in(t time.Time) time.Time {
+	if o.Location == nil {
+		return t
+	}
+
+	return t.In(o.Location)
+}
+
+func (o Options) instrument(figi string) (sdk.Instrument, bool) {
+	if o.Instruments == nil {
+		return sdk.Instrument{}, false
+	}
+
+	return o.Instruments.InstrumentByFIGI(figi)
+}
+
+func missingColumn(name string) error {
+	return fmt.Errorf("%w %q", ErrMissingColumn, name)
+}
@@ -0,0 +1,168 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	sdk "github.com/Tinkoff/invest-openapi-go-sdk"
+)
+
+type resolver map[string]sdk.Instrument
+
+func (r resolver) InstrumentByFIGI(figi string) (sdk.Instrument, bool) {
+	i, ok := r[figi]
+	return i, ok
+}
+
+func testCandles() []sdk.Candle {
+	ts := time.Date(2021, 3, 1, 7, 0, 0, 0, time.UTC)
+
+	return []sdk.Candle{
+		{FIGI: "BBG004730N88", Interval: sdk.CandleInterval1Hour, OpenPrice: 271.5, ClosePrice: 272.01, HighPrice: 273, LowPrice: 270.8, Volume: 120345, TS: ts},
+		{FIGI: "BBG004730N88", Interval: sdk.CandleInterval1Hour, OpenPrice: 272.01, ClosePrice: 271.9, HighPrice: 272.5, LowPrice: 271.1, Volume: 98000, TS: ts.Add(time.Hour)},
+	}
+}
+
+func testOperations() []sdk.Operation {
+	ts := time.Date(2021, 3, 1, 10, 30, 15, 0, time.UTC)
+
+	return []sdk.Operation{
+		{
+			ID:               "1",
+			Status:           sdk.OperationStatusDone,
+			Trades:           []sdk.Trade{{ID: "t1", DateTime: ts, Price: 271.5, Quantity: 10}},
+			Commission:       sdk.MoneyAmount{Currency: sdk.RUB, Value: -1.36},
+			Currency:         sdk.RUB,
+			Payment:          -2715,
+			Price:            271.5,
+			Quantity:         10,
+			QuantityExecuted: 10,
+			FIGI:             "BBG004730N88",
+			InstrumentType:   sdk.InstrumentTypeStock,
+			DateTime:         ts,
+			OperationType:    sdk.BUY,
+		},
+		{
+			ID:            "2",
+			Status:        sdk.OperationStatusDone,
+			Currency:      sdk.RUB,
+			Payment:       100000,
+			DateTime:      ts.Add(-time.Hour),
+			OperationType: sdk.OperationTypePayIn,
+		},
+	}
+}
+
+func testOptions() Options {
+	return Options{
+		Location: time.UTC,
+		Instruments: resolver{"BBG004730N88": {
+			FIGI: "BBG004730N88", Ticker: "SBER", ISIN: "RU0009029540", Name: "Сбербанк России",
+			Currency: sdk.RUB, Lot: 10, MinPriceIncrement: 0.01,
+		}},
+	}
+}
+
+func TestCandlesCSV(t *testing.T) {
+	candles := testCandles()
+
+	var buf bytes.Buffer
+	if err := WriteCandlesCSV(&buf, candles, testOptions()); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := append(CandleColumns(), InstrumentColumns()...); !reflect.DeepEqual(records[0], want) {
+		t.Fatalf("header %v, want %v", records[0], want)
+	}
+	if got := records[1][len(CandleColumns())]; got != "SBER" {
+		t.Fatalf("ticker column %q", got)
+	}
+
+	got, err := ReadCandlesCSV(&buf, Options{Location: time.UTC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, candles) {
+		t.Fatalf("got %+v\nwant %+v", got, candles)
+	}
+}
+
+func TestCandlesCSVMissingColumn(t *testing.T) {
+	_, err := ReadCandlesCSV(strings.NewReader("figi,interval,time\n"), Options{})
+	if !errors.Is(err, ErrMissingColumn) {
+		t.Fatalf("want ErrMissingColumn, got %v", err)
+	}
+}
+
+func TestOperationsCSV(t *testing.T) {
+	operations := testOperations()
+
+	var buf bytes.Buffer
+	if err := WriteOperationsCSV(&buf, operations, Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadOperationsCSV(&buf, Options{Location: time.UTC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, operations) {
+		t.Fatalf("got %+v\nwant %+v", got, operations)
+	}
+}
+
+func TestCandlesParquet(t *testing.T) {
+	candles := testCandles()
+
+	var buf bytes.Buffer
+	if err := WriteCandlesParquet(&buf, candles, testOptions()); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadCandlesParquet(&buf, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, candles) {
+		t.Fatalf("got %+v\nwant %+v", got, candles)
+	}
+}
+
+func TestOperationsParquet(t *testing.T) {
+	operations := testOperations()
+
+	var buf bytes.Buffer
+	if err := WriteOperationsParquet(&buf, operations, testOptions()); err != nil {
+		t.Fatal(err)
+	}
+
+	moscow := time.FixedZone("MSK", 3*60*60)
+	got, err := ReadOperationsParquet(&buf, Options{Location: moscow})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(operations) {
+		t.Fatalf("got %d operations", len(got))
+	}
+	for i := range got {
+		if got[i].DateTime.Location() != moscow {
+			t.Fatalf("operation %d time %s isn't in location of options", i, got[i].DateTime)
+		}
+		got[i].DateTime = got[i].DateTime.UTC()
+		for j := range got[i].Trades {
+			got[i].Trades[j].DateTime = got[i].Trades[j].DateTime.UTC()
+		}
+	}
+	if !reflect.DeepEqual(got, operations) {
+		t.Fatalf("got %+v\nwant %+v", got, operations)
+	}
+}
@@ -0,0 +1,287 @@
+package export
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	sdk "github.com/Tinkoff/invest-openapi-go-sdk"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// parquetParallelism count of goroutines used to encode and decode Parquet.
+const parquetParallelism = 1
+
+var errReadOnly = errors.New("read only file")
+
+type (
+	// candleRow Parquet schema of candle.
+	candleRow struct {
+		FIGI     string  `parquet:"name=figi, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+		Interval string  `parquet:"name=interval, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+		Time     int64   `parquet:"name=time, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+		Open     float64 `parquet:"name=open, type=DOUBLE"`
+		High     float64 `parquet:"name=high, type=DOUBLE"`
+		Low      float64 `parquet:"name=low, type=DOUBLE"`
+		Close    float64 `parquet:"name=close, type=DOUBLE"`
+		Volume   float64 `parquet:"name=volume, type=DOUBLE"`
+		instrumentColumns
+	}
+
+	// operationRow Parquet schema of operation, trades are JSON array.
+	operationRow struct {
+		ID                 string  `parquet:"name=id, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Status             string  `parquet:"name=status, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+		FIGI               string  `parquet:"name=figi, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+		InstrumentType     string  `parquet:"name=instrument_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+		OperationType      string  `parquet:"name=operation_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+		Date               int64   `parquet:"name=date, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+		Currency           string  `parquet:"name=currency, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+		Payment            float64 `parquet:"name=payment, type=DOUBLE"`
+		Price              float64 `parquet:"name=price, type=DOUBLE"`
+		Quantity           int64   `parquet:"name=quantity, type=INT64"`
+		QuantityExecuted   int64   `parquet:"name=quantity_executed, type=INT64"`
+		Commission         float64 `parquet:"name=commission, type=DOUBLE"`
+		CommissionCurrency string  `parquet:"name=commission_currency, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+		IsMarginCall       bool    `parquet:"name=is_margin_call, type=BOOLEAN"`
+		Trades             string  `parquet:"name=trades, type=BYTE_ARRAY, convertedtype=UTF8"`
+		instrumentColumns
+	}
+
+	// instrumentColumns optional instrument metadata, nulls if instrument isn't resolved.
+	instrumentColumns struct {
+		Ticker             *string  `parquet:"name=ticker, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+		ISIN               *string  `parquet:"name=isin, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+		Name               *string  `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+		InstrumentCurrency *string  `parquet:"name=instrument_currency, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+		Lot                *int64   `parquet:"name=lot, type=INT64, repetitiontype=OPTIONAL"`
+		MinPriceIncrement  *float64 `parquet:"name=min_price_increment, type=DOUBLE, repetitiontype=OPTIONAL"`
+	}
+
+	// memoryFile read only source.ParquetFile over bytes.
+	memoryFile struct {
+		*bytes.Reader
+		data []byte
+	}
+)
+
+// WriteCandlesParquet writes candles to w as Snappy compressed Parquet file.
+func WriteCandlesParquet(w io.Writer, candles []sdk.Candle, opts Options) error {
+	pw, err := writer.NewParquetWriterFromWriter(w, new(candleRow), parquetParallelism)
+	if err != nil {
+		return fmt.Errorf("create parquet writer: %w", err)
+	}
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+
+	for _, c := range candles {
+		row := candleRow{
+			FIGI:              c.FIGI,
+			Interval:          string(c.Interval),
+			Time:              toMillis(c.TS),
+			Open:              c.OpenPrice,
+			High:              c.HighPrice,
+			Low:               c.LowPrice,
+			Close:             c.ClosePrice,
+			Volume:            c.Volume,
+			instrumentColumns: newInstrumentColumns(c.FIGI, opts),
+		}
+		if err := pw.Write(row); err != nil {
+			return fmt.Errorf("write candle: %w", err)
+		}
+	}
+
+	return pw.WriteStop()
+}
+
+// ReadCandlesParquet reads candles written by WriteCandlesParquet.
+func ReadCandlesParquet(r io.Reader, opts Options) ([]sdk.Candle, error) {
+	pr, err := openParquet(r, new(candleRow))
+	if err != nil {
+		return nil, err
+	}
+	defer pr.ReadStop()
+
+	rows := make([]candleRow, pr.GetNumRows())
+	if err := pr.Read(&rows); err != nil {
+		return nil, fmt.Errorf("read parquet: %w", err)
+	}
+
+	opts = parquetOptions(opts)
+	candles := make([]sdk.Candle, 0, len(rows))
+	for _, row := range rows {
+		candles = append(candles, sdk.Candle{
+			FIGI:       row.FIGI,
+			Interval:   sdk.CandleInterval(row.Interval),
+			TS:         opts.in(fromMillis(row.Time)),
+			OpenPrice:  row.Open,
+			HighPrice:  row.High,
+			LowPrice:   row.Low,
+			ClosePrice: row.Close,
+			Volume:     row.Volume,
+		})
+	}
+
+	return candles, nil
+}
+
+// WriteOperationsParquet writes operations to w as Snappy compressed Parquet file.
+func WriteOperationsParquet(w io.Writer, operations []sdk.Operation, opts Options) error {
+	pw, err := writer.NewParquetWriterFromWriter(w, new(operationRow), parquetParallelism)
+	if err != nil {
+		return fmt.Errorf("create parquet writer: %w", err)
+	}
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+
+	for _, op := range operations {
+		trades, err := marshalTrades(op.Trades)
+		if err != nil {
+			return fmt.Errorf("operation %s: %w", op.ID, err)
+		}
+
+		row := operationRow{
+			ID:                 op.ID,
+			Status:             string(op.Status),
+			FIGI:               op.FIGI,
+			InstrumentType:     string(op.InstrumentType),
+			OperationType:      string(op.OperationType),
+			Date:               toMillis(op.DateTime),
+			Currency:           string(op.Currency),
+			Payment:            op.Payment,
+			Price:              op.Price,
+			Quantity:           int64(op.Quantity),
+			QuantityExecuted:   int64(op.QuantityExecuted),
+			Commission:         op.Commission.Value,
+			CommissionCurrency: string(op.Commission.Currency),
+			IsMarginCall:       op.IsMarginCall,
+			Trades:             trades,
+			instrumentColumns:  newInstrumentColumns(op.FIGI, opts),
+		}
+		if err := pw.Write(row); err != nil {
+			return fmt.Errorf("write operation %s: %w", op.ID, err)
+		}
+	}
+
+	return pw.WriteStop()
+}
+
+// ReadOperationsParquet reads operations written by WriteOperationsParquet.
+func ReadOperationsParquet(r io.Reader, opts Options) ([]sdk.Operation, error) {
+	pr, err := openParquet(r, new(operationRow))
+	if err != nil {
+		return nil, err
+	}
+	defer pr.ReadStop()
+
+	rows := make([]operationRow, pr.GetNumRows())
+	if err := pr.Read(&rows); err != nil {
+		return nil, fmt.Errorf("read parquet: %w", err)
+	}
+
+	opts = parquetOptions(opts)
+	operations := make([]sdk.Operation, 0, len(rows))
+	for _, row := range rows {
+		trades, err := unmarshalTrades(row.Trades, opts)
+		if err != nil {
+			return nil, fmt.Errorf("operation %s trades: %w", row.ID, err)
+		}
+
+		operations = append(operations, sdk.Operation{
+			ID:               row.ID,
+			Status:           sdk.OperationStatus(row.Status),
+			FIGI:             row.FIGI,
+			InstrumentType:   sdk.InstrumentType(row.InstrumentType),
+			OperationType:    sdk.OperationType(row.OperationType),
+			DateTime:         opts.in(fromMillis(row.Date)),
+			Currency:         sdk.Currency(row.Currency),
+			Payment:          row.Payment,
+			Price:            row.Price,
+			Quantity:         int(row.Quantity),
+			QuantityExecuted: int(row.QuantityExecuted),
+			Commission: sdk.MoneyAmount{
+				Value:    row.Commission,
+				Currency: sdk.Currency(row.CommissionCurrency),
+			},
+			IsMarginCall: row.IsMarginCall,
+			Trades:       trades,
+		})
+	}
+
+	return operations, nil
+}
+
+// openParquet reads whole r into memory and opens it with schema.
+func openParquet(r io.Reader, schema interface{}) (*reader.ParquetReader, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, err := reader.NewParquetReader(newMemoryFile(data), schema, parquetParallelism)
+	if err != nil {
+		return nil, fmt.Errorf("open parquet: %w", err)
+	}
+
+	return pr, nil
+}
+
+func newInstrumentColumns(figi string, opts Options) instrumentColumns {
+	instrument, ok := opts.instrument(figi)
+	if !ok {
+		return instrumentColumns{}
+	}
+
+	currency := string(instrument.Currency)
+	lot := int64(instrument.Lot)
+
+	return instrumentColumns{
+		Ticker:             &instrument.Ticker,
+		ISIN:               &instrument.ISIN,
+		Name:               &instrument.Name,
+		InstrumentCurrency: &currency,
+		Lot:                &lot,
+		MinPriceIncrement:  &instrument.MinPriceIncrement,
+	}
+}
+
+// parquetOptions returns times in UTC by default.
+func parquetOptions(opts Options) Options {
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+
+	return opts
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func fromMillis(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
+
+func newMemoryFile(data []byte) *memoryFile {
+	return &memoryFile{Reader: bytes.NewReader(data), data: data}
+}
+
+func (f *memoryFile) Open(string) (source.ParquetFile, error) {
+	return newMemoryFile(f.data), nil
+}
+
+func (f *memoryFile) Create(string) (source.ParquetFile, error) {
+	return nil, errReadOnly
+}
+
+func (f *memoryFile) Write([]byte) (int, error) {
+	return 0, errReadOnly
+}
+
+func (f *memoryFile) Close() error {
+	return nil
+}
@@ -3,6 +3,14 @@ module github.com/Tinkoff/invest-openapi-go-sdk
 go 1.15
 
 require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/gorilla/websocket v1.4.2
+	github.com/klauspost/compress v1.15.9 // indirect
 	github.com/pkg/errors v0.9.1
+	github.com/stretchr/testify v1.7.1 // indirect
+	github.com/xitongsys/parquet-go v1.6.2
+	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
+	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
+	gopkg.in/yaml.v2 v2.4.0
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,359 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	_ InstrumentSource   = &RestClient{}
+	_ InstrumentResolver = &InstrumentCatalog{}
+)
+
+type (
+	// InstrumentSource provides full instrument lists for InstrumentCatalog.
+	InstrumentSource interface {
+		Stocks(ctx context.Context) ([]Instrument, error)
+		Bonds(ctx context.Context) ([]Instrument, error)
+		ETFs(ctx context.Context) ([]Instrument, error)
+		Currencies(ctx context.Context) ([]Instrument, error)
+	}
+
+	// InstrumentResolver resolves instrument reference data (lot, min price increment and etc.) by FIGI.
+	InstrumentResolver interface {
+		InstrumentByFIGI(figi string) (Instrument, bool)
+	}
+
+	// InstrumentCatalog in-memory registry of instruments indexed by FIGI, ticker, ISIN and name.
+	// It is safe for concurrent use, lookups are not blocked by refresh.
+	InstrumentCatalog struct {
+		source InstrumentSource
+
+		mu    sync.RWMutex
+		index *instrumentIndex
+	}
+
+	instrumentIndex struct {
+		instruments []Instrument
+		updatedAt   time.Time
+
+		byFIGI   map[string]int
+		byTicker map[string][]int
+		byISIN   map[string][]int
+		byName   []nameEntry // sorted by name
+	}
+
+	nameEntry struct {
+		name string // lower case
+		idx  int
+	}
+
+	catalogSnapshot struct {
+		UpdatedAt   time.Time    `json:"updatedAt"`
+		Instruments []Instrument `json:"instruments"`
+	}
+)
+
+// NewInstrumentCatalog returns empty catalog which loads instruments from source.
+// Call Refresh or LoadFile before lookups.
+func NewInstrumentCatalog(source InstrumentSource) *InstrumentCatalog {
+	return &InstrumentCatalog{
+		source: source,
+		index:  newInstrumentIndex(nil, time.Time{}),
+	}
+}
+
+// Refresh loads stocks, bonds, etfs and currencies from source and replaces catalog content.
+func (c *InstrumentCatalog) Refresh(ctx context.Context) error {
+	loaders := []struct {
+		name string
+		load func(ctx context.Context) ([]Instrument, error)
+	}{
+		{"stocks", c.source.Stocks},
+		{"bonds", c.source.Bonds},
+		{"etfs", c.source.ETFs},
+		{"currencies", c.source.Currencies},
+	}
+
+	var instruments []Instrument
+	for _, l := range loaders {
+		list, err := l.load(ctx)
+		if err != nil {
+			return fmt.Errorf("load %s: %w", l.name, err)
+		}
+		instruments = append(instruments, list...)
+	}
+
+	c.replace(newInstrumentIndex(instruments, time.Now()))
+
+	return nil
+}
+
+// AutoRefresh calls Refresh every interval until ctx is done.
+// Refresh errors are passed to onError (may be nil), previous catalog content is kept in this case.
+func (c *InstrumentCatalog) AutoRefresh(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Refresh(ctx); err != nil && onError != nil && ctx.Err() == nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// UpdatedAt returns time of the last successful refresh or time of the loaded snapshot.
+func (c *InstrumentCatalog) UpdatedAt() time.Time {
+	return c.current().updatedAt
+}
+
+// Len returns count of instruments in catalog.
+func (c *InstrumentCatalog) Len() int {
+	return len(c.current().instruments)
+}
+
+// Instruments returns copy of all instruments in catalog.
+func (c *InstrumentCatalog) Instruments() []Instrument {
+	index := c.current()
+
+	instruments := make([]Instrument, len(index.instruments))
+	copy(instruments, index.instruments)
+
+	return instruments
+}
+
+// InstrumentByFIGI returns instrument by FIGI.
+func (c *InstrumentCatalog) InstrumentByFIGI(figi string) (Instrument, bool) {
+	index := c.current()
+
+	idx, ok := index.byFIGI[strings.ToUpper(figi)]
+	if !ok {
+		return Instrument{}, false
+	}
+
+	return index.instruments[idx], true
+}
+
+// InstrumentByTicker returns instruments by ticker, ticker is unique only within one exchange so result may contain several instruments.
+func (c *InstrumentCatalog) InstrumentByTicker(ticker string) []Instrument {
+	index := c.current()
+
+	return index.collect(index.byTicker[strings.ToUpper(ticker)])
+}
+
+// InstrumentByISIN returns instruments by ISIN, the same security may be traded on several exchanges so result may contain several instruments.
+func (c *InstrumentCatalog) InstrumentByISIN(isin string) []Instrument {
+	index := c.current()
+
+	return index.collect(index.byISIN[strings.ToUpper(isin)])
+}
+
+// InstrumentsByNamePrefix returns instruments which name starts with prefix, case insensitive.
+func (c *InstrumentCatalog) InstrumentsByNamePrefix(prefix string) []Instrument {
+	index := c.current()
+	prefix = strings.ToLower(prefix)
+
+	from := sort.Search(len(index.byName), func(i int) bool {
+		return index.byName[i].name >= prefix
+	})
+
+	var instruments []Instrument
+	for i := from; i < len(index.byName) && strings.HasPrefix(index.byName[i].name, prefix); i++ {
+		instruments = append(instruments, index.instruments[index.byName[i].idx])
+	}
+
+	return instruments
+}
+
+// Lookup resolves instruments by any identifier: FIGI, ticker or ISIN (in this order).
+func (c *InstrumentCatalog) Lookup(id string) []Instrument {
+	index := c.current()
+	id = strings.ToUpper(id)
+
+	if idx, ok := index.byFIGI[id]; ok {
+		return []Instrument{index.instruments[idx]}
+	}
+	if ids, ok := index.byTicker[id]; ok {
+		return index.collect(ids)
+	}
+
+	return index.collect(index.byISIN[id])
+}
+
+// Lot returns lot size of instrument by FIGI.
+func (c *InstrumentCatalog) Lot(figi string) (int, bool) {
+	instrument, ok := c.InstrumentByFIGI(figi)
+	if !ok {
+		return 0, false
+	}
+
+	return instrument.Lot, true
+}
+
+// MinPriceIncrement returns price tick of instrument by FIGI.
+func (c *InstrumentCatalog) MinPriceIncrement(figi string) (float64, bool) {
+	instrument, ok := c.InstrumentByFIGI(figi)
+	if !ok {
+		return 0, false
+	}
+
+	return instrument.MinPriceIncrement, true
+}
+
+// RoundPrice rounds price to the nearest price tick of instrument by FIGI.
+// Price is returned as is if instrument has no price tick.
+func (c *InstrumentCatalog) RoundPrice(figi string, price float64) (float64, bool) {
+	tick, ok := c.MinPriceIncrement(figi)
+	if !ok {
+		return 0, false
+	}
+	if tick <= 0 {
+		return price, true
+	}
+
+	// Rounding to tick decimals removes float artifacts like 100.10000000000001.
+	decimals := 0
+	if s := strconv.FormatFloat(tick, 'f', -1, 64); strings.Contains(s, ".") {
+		decimals = len(s) - strings.Index(s, ".") - 1
+	}
+	precision := math.Pow10(decimals)
+
+	return math.Round(math.Round(price/tick)*tick*precision) / precision, true
+}
+
+// Save writes catalog snapshot as json to w.
+func (c *InstrumentCatalog) Save(w io.Writer) error {
+	index := c.current()
+
+	err := json.NewEncoder(w).Encode(catalogSnapshot{
+		UpdatedAt:   index.updatedAt,
+		Instruments: index.instruments,
+	})
+	if err != nil {
+		return fmt.Errorf("encode json: %w", err)
+	}
+
+	return nil
+}
+
+// Load replaces catalog content by snapshot written by Save.
+func (c *InstrumentCatalog) Load(r io.Reader) error {
+	var snapshot catalogSnapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return fmt.Errorf("decode json: %w", err)
+	}
+
+	c.replace(newInstrumentIndex(snapshot.Instruments, snapshot.UpdatedAt))
+
+	return nil
+}
+
+// SaveFile atomically writes catalog snapshot to file by path.
+func (c *InstrumentCatalog) SaveFile(path string) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := c.Save(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close temp file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("rename temp file: %w", err)
+	}
+
+	return nil
+}
+
+// LoadFile replaces catalog content by snapshot from file by path. Use it for fast startup before Refresh.
+func (c *InstrumentCatalog) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+
+	return c.Load(f)
+}
+
+func (c *InstrumentCatalog) current() *instrumentIndex {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.index
+}
+
+func (c *InstrumentCatalog) replace(index *instrumentIndex) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.index = index
+}
+
+func newInstrumentIndex(instruments []Instrument, updatedAt time.Time) *instrumentIndex {
+	index := &instrumentIndex{
+		instruments: instruments,
+		updatedAt:   updatedAt,
+		byFIGI:      make(map[string]int, len(instruments)),
+		byTicker:    make(map[string][]int, len(instruments)),
+		byISIN:      make(map[string][]int, len(instruments)),
+		byName:      make([]nameEntry, 0, len(instruments)),
+	}
+
+	for i, instrument := range instruments {
+		index.byFIGI[strings.ToUpper(instrument.FIGI)] = i
+
+		if instrument.Ticker != "" {
+			ticker := strings.ToUpper(instrument.Ticker)
+			index.byTicker[ticker] = append(index.byTicker[ticker], i)
+		}
+		if instrument.ISIN != "" {
+			isin := strings.ToUpper(instrument.ISIN)
+			index.byISIN[isin] = append(index.byISIN[isin], i)
+		}
+		if instrument.Name != "" {
+			index.byName = append(index.byName, nameEntry{name: strings.ToLower(instrument.Name), idx: i})
+		}
+	}
+
+	sort.Slice(index.byName, func(i, j int) bool {
+		return index.byName[i].name < index.byName[j].name
+	})
+
+	return index
+}
+
+func (i *instrumentIndex) collect(ids []int) []Instrument {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	instruments := make([]Instrument, 0, len(ids))
+	for _, idx := range ids {
+		instruments = append(instruments, i.instruments[idx])
+	}
+
+	return instruments
+}
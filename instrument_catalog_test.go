@@ -0,0 +1,307 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeInstrumentSource returns the same instruments on every call, stocks fail while err is set.
+type fakeInstrumentSource struct {
+	stocks, bonds, etfs, currencies []Instrument
+
+	err   error
+	calls int32 // count of Stocks calls
+}
+
+func (s *fakeInstrumentSource) Stocks(context.Context) ([]Instrument, error) {
+	atomic.AddInt32(&s.calls, 1)
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return s.stocks, nil
+}
+
+func (s *fakeInstrumentSource) Bonds(context.Context) ([]Instrument, error) {
+	return s.bonds, nil
+}
+
+func (s *fakeInstrumentSource) ETFs(context.Context) ([]Instrument, error) {
+	return s.etfs, nil
+}
+
+func (s *fakeInstrumentSource) Currencies(context.Context) ([]Instrument, error) {
+	return s.currencies, nil
+}
+
+func newTestInstrumentSource() *fakeInstrumentSource {
+	return &fakeInstrumentSource{
+		stocks: []Instrument{
+			{FIGI: "BBG004730N88", Ticker: "SBER", ISIN: "RU0009029540", Name: "Сбер Банк", MinPriceIncrement: 0.01, Lot: 10, Currency: RUB, Type: InstrumentTypeStock},
+			{FIGI: "BBG000B9XRY4", Ticker: "AAPL", ISIN: "US0378331005", Name: "Apple", MinPriceIncrement: 0.01, Lot: 1, Currency: USD, Type: InstrumentTypeStock},
+			{FIGI: "BBG000BPH459", Ticker: "MSFT", ISIN: "US5949181045", Name: "Microsoft Corporation", MinPriceIncrement: 0.01, Lot: 1, Currency: USD, Type: InstrumentTypeStock},
+			{FIGI: "BBG000BVPV84", Ticker: "AMZN", ISIN: "US0231351067", Name: "Amazon.com", MinPriceIncrement: 0.01, Lot: 1, Currency: USD, Type: InstrumentTypeStock},
+			// the same security traded on other exchange
+			{FIGI: "BBG00ZSPBAPL", Ticker: "AAPL@GS", ISIN: "US0378331005", Name: "Apple (SPB)", MinPriceIncrement: 0.5, Lot: 1, Currency: USD, Type: InstrumentTypeStock},
+		},
+		bonds: []Instrument{
+			{FIGI: "BBG00T22WKV5", Ticker: "SU26234RMFS3", ISIN: "RU000A101QE0", Name: "ОФЗ 26234", MinPriceIncrement: 0.0025, Lot: 1, Currency: RUB, Type: InstrumentTypeBond},
+		},
+		etfs: []Instrument{
+			{FIGI: "BBG00ZZZZETF", Ticker: "TNOM", Name: "Нет шага цены", Lot: 1, Currency: RUB, Type: InstrumentTypeEtf},
+		},
+		currencies: []Instrument{
+			{FIGI: "BBG0013HGFT4", Ticker: "USD000UTSTOM", Name: "Доллар США", MinPriceIncrement: 0.0025, Lot: 1000, Currency: RUB, Type: InstrumentTypeCurrency},
+		},
+	}
+}
+
+func newTestCatalog(t *testing.T) *InstrumentCatalog {
+	t.Helper()
+
+	catalog := NewInstrumentCatalog(newTestInstrumentSource())
+	if err := catalog.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return catalog
+}
+
+func instrumentFIGIs(instruments []Instrument) []string {
+	result := make([]string, len(instruments))
+	for i, instrument := range instruments {
+		result[i] = instrument.FIGI
+	}
+
+	return result
+}
+
+func TestInstrumentCatalogRefresh(t *testing.T) {
+	catalog := newTestCatalog(t)
+
+	if catalog.Len() != 8 {
+		t.Errorf("want 8 instruments, got %d", catalog.Len())
+	}
+	if catalog.UpdatedAt().IsZero() {
+		t.Error("refresh time isn't set")
+	}
+
+	source := newTestInstrumentSource()
+	source.err = errors.New("unavailable")
+	failed := NewInstrumentCatalog(source)
+	if err := failed.Refresh(context.Background()); !errors.Is(err, source.err) {
+		t.Errorf("want source error, got %v", err)
+	}
+}
+
+func TestInstrumentCatalogLookup(t *testing.T) {
+	catalog := newTestCatalog(t)
+
+	instrument, ok := catalog.InstrumentByFIGI("bbg004730n88")
+	if !ok || instrument.Ticker != "SBER" {
+		t.Errorf("InstrumentByFIGI = %+v, %v", instrument, ok)
+	}
+	if _, ok := catalog.InstrumentByFIGI("BBG000000000"); ok {
+		t.Error("unknown FIGI is found")
+	}
+
+	tests := []struct {
+		name   string
+		lookup func(string) []Instrument
+		id     string
+		want   []string
+	}{
+		{name: "ticker", lookup: catalog.InstrumentByTicker, id: "sber", want: []string{"BBG004730N88"}},
+		{name: "unknown ticker", lookup: catalog.InstrumentByTicker, id: "NOPE", want: []string{}},
+		{name: "isin", lookup: catalog.InstrumentByISIN, id: "RU0009029540", want: []string{"BBG004730N88"}},
+		{name: "isin on several exchanges", lookup: catalog.InstrumentByISIN, id: "us0378331005", want: []string{"BBG000B9XRY4", "BBG00ZSPBAPL"}},
+		{name: "lookup by figi", lookup: catalog.Lookup, id: "BBG000BPH459", want: []string{"BBG000BPH459"}},
+		{name: "lookup by ticker", lookup: catalog.Lookup, id: "amzn", want: []string{"BBG000BVPV84"}},
+		{name: "lookup by isin", lookup: catalog.Lookup, id: "RU000A101QE0", want: []string{"BBG00T22WKV5"}},
+		{name: "lookup unknown", lookup: catalog.Lookup, id: "XX0000000000", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := instrumentFIGIs(tt.lookup(tt.id)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstrumentCatalogNamePrefix(t *testing.T) {
+	catalog := newTestCatalog(t)
+
+	tests := map[string][]string{
+		"apple":  {"BBG000B9XRY4", "BBG00ZSPBAPL"},
+		"APPLE ": {"BBG00ZSPBAPL"},
+		"ам":     {},
+		"a":      {"BBG000BVPV84", "BBG000B9XRY4", "BBG00ZSPBAPL"},
+		"офз":    {"BBG00T22WKV5"},
+		"z":      {},
+	}
+
+	for prefix, want := range tests {
+		if got := instrumentFIGIs(catalog.InstrumentsByNamePrefix(prefix)); !reflect.DeepEqual(got, want) {
+			t.Errorf("InstrumentsByNamePrefix(%q) = %v, want %v", prefix, got, want)
+		}
+	}
+}
+
+func TestInstrumentCatalogRoundPrice(t *testing.T) {
+	catalog := newTestCatalog(t)
+
+	tests := []struct {
+		figi  string
+		price float64
+		want  float64
+	}{
+		// tick 0.01
+		{figi: "BBG004730N88", price: 100.104, want: 100.1},
+		{figi: "BBG004730N88", price: 100.106, want: 100.11},
+		{figi: "BBG004730N88", price: 0.1 + 0.2, want: 0.3},
+		// tick 0.0025
+		{figi: "BBG00T22WKV5", price: 1.2351, want: 1.235},
+		{figi: "BBG00T22WKV5", price: 1.2364, want: 1.2375},
+		{figi: "BBG0013HGFT4", price: 73.1011, want: 73.1},
+		// tick 0.5
+		{figi: "BBG00ZSPBAPL", price: 10.2, want: 10},
+		{figi: "BBG00ZSPBAPL", price: 10.3, want: 10.5},
+		{figi: "BBG00ZSPBAPL", price: 10.76, want: 11},
+		// no tick
+		{figi: "BBG00ZZZZETF", price: 1.23456, want: 1.23456},
+	}
+
+	for _, tt := range tests {
+		got, ok := catalog.RoundPrice(tt.figi, tt.price)
+		if !ok || got != tt.want {
+			t.Errorf("RoundPrice(%s, %v) = %v, %v, want %v", tt.figi, tt.price, got, ok, tt.want)
+		}
+	}
+
+	if _, ok := catalog.RoundPrice("BBG000000000", 1); ok {
+		t.Error("price of unknown instrument is rounded")
+	}
+}
+
+func TestInstrumentCatalogSaveLoad(t *testing.T) {
+	catalog := newTestCatalog(t)
+
+	var buf bytes.Buffer
+	if err := catalog.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewInstrumentCatalog(nil)
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	assertSameCatalog(t, catalog, loaded)
+
+	path := filepath.Join(t.TempDir(), "instruments.json")
+	if err := catalog.SaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+	fromFile := NewInstrumentCatalog(nil)
+	if err := fromFile.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	assertSameCatalog(t, catalog, fromFile)
+
+	if err := loaded.Load(bytes.NewBufferString("{")); err == nil {
+		t.Error("broken snapshot is loaded")
+	}
+	if loaded.Len() != catalog.Len() {
+		t.Error("failed load replaced catalog content")
+	}
+}
+
+func assertSameCatalog(t *testing.T, want, got *InstrumentCatalog) {
+	t.Helper()
+
+	if !reflect.DeepEqual(got.Instruments(), want.Instruments()) {
+		t.Errorf("instruments %+v, want %+v", got.Instruments(), want.Instruments())
+	}
+	if !got.UpdatedAt().Equal(want.UpdatedAt()) {
+		t.Errorf("updated at %s, want %s", got.UpdatedAt(), want.UpdatedAt())
+	}
+	if instruments := got.InstrumentByISIN("US0378331005"); len(instruments) != 2 {
+		t.Errorf("loaded catalog isn't indexed: %v", instruments)
+	}
+}
+
+func TestInstrumentCatalogAutoRefresh(t *testing.T) {
+	source := newTestInstrumentSource()
+	catalog := NewInstrumentCatalog(source)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		catalog.AutoRefresh(ctx, 5*time.Millisecond, nil)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for catalog.Len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if catalog.Len() == 0 {
+		t.Fatal("catalog isn't refreshed")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("AutoRefresh doesn't stop on context cancel")
+	}
+
+	calls := atomic.LoadInt32(&source.calls)
+	time.Sleep(20 * time.Millisecond)
+	if got := atomic.LoadInt32(&source.calls); got != calls {
+		t.Errorf("refreshed %d times after stop", got-calls)
+	}
+}
+
+func TestInstrumentCatalogAutoRefreshError(t *testing.T) {
+	catalog := newTestCatalog(t)
+	updatedAt := catalog.UpdatedAt()
+
+	source := newTestInstrumentSource()
+	source.err = errors.New("unavailable")
+	catalog.source = source
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errs := make(chan error, 1)
+	go catalog.AutoRefresh(ctx, 5*time.Millisecond, func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, source.err) {
+			t.Errorf("want source error, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("refresh error isn't reported")
+	}
+
+	if catalog.Len() != 8 || !catalog.UpdatedAt().Equal(updatedAt) {
+		t.Error("failed refresh replaced catalog content")
+	}
+}
@@ -0,0 +1,207 @@
+package sdk
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// MinSearchScore is the lowest score of match returned by SearchInstruments.
+const MinSearchScore = 0.6
+
+// InstrumentMatch contains instrument found by SearchInstruments and match score in interval 0 < x <= 1.
+type InstrumentMatch struct {
+	Instrument Instrument
+	Score      float64
+}
+
+// SearchInstruments returns instruments ranked by fuzzy match of query with instrument ticker and name.
+// Returns at most limit matches, all matches if limit <= 0.
+func (c *InstrumentCatalog) SearchInstruments(query string, limit int) []InstrumentMatch {
+	q := normalizeName(query)
+	if q == "" {
+		return nil
+	}
+	terms := strings.Fields(q)
+
+	index := c.current()
+
+	var matches []InstrumentMatch
+	for _, instrument := range index.instruments {
+		score := matchScore(q, terms, instrument)
+		if score >= MinSearchScore {
+			matches = append(matches, InstrumentMatch{Instrument: instrument, Score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+
+		return matches[i].Instrument.Ticker < matches[j].Instrument.Ticker
+	})
+
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches
+}
+
+func matchScore(query string, terms []string, instrument Instrument) float64 {
+	ticker := strings.ToLower(instrument.Ticker)
+	name := normalizeName(instrument.Name)
+
+	switch {
+	case ticker != "" && query == ticker:
+		return 1
+	case name != "" && query == name:
+		return 0.95
+	}
+
+	var score float64
+	if ticker != "" && strings.HasPrefix(ticker, query) {
+		score = maxScore(score, 0.7+0.2*coverage(query, ticker))
+	}
+	if name != "" && strings.HasPrefix(name, query) {
+		score = maxScore(score, 0.7+0.2*coverage(query, name))
+	}
+	if matched := matchedTerms(terms, strings.Fields(name)); matched == len(terms) {
+		score = maxScore(score, 0.75)
+	} else {
+		score = maxScore(score, 0.5*float64(matched)/float64(len(terms)))
+	}
+
+	score = maxScore(score, 0.8*jaroWinkler(query, name))
+	score = maxScore(score, 0.8*jaroWinkler(query, ticker))
+
+	return score
+}
+
+// normalizeName returns lower case name without punctuation and legal forms.
+func normalizeName(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	words := fields[:0]
+	for _, f := range fields {
+		if !isLegalForm(f) {
+			words = append(words, f)
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// isLegalForm reports whether word is legal form dropped from names before matching,
+// so "Apple Inc." matches "Apple".
+func isLegalForm(word string) bool {
+	switch word {
+	case "inc", "corp", "corporation", "co", "company", "ltd", "plc", "ag", "sa", "nv", "se",
+		"пао", "оао", "ао", "ооо":
+		return true
+	default:
+		return false
+	}
+}
+
+// matchedTerms returns count of terms which are prefixes of any of words.
+func matchedTerms(terms, words []string) int {
+	var matched int
+	for _, term := range terms {
+		for _, word := range words {
+			if strings.HasPrefix(word, term) {
+				matched++
+				break
+			}
+		}
+	}
+
+	return matched
+}
+
+func coverage(part, whole string) float64 {
+	return float64(len([]rune(part))) / float64(len([]rune(whole)))
+}
+
+func maxScore(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+// jaroWinkler returns Jaro-Winkler similarity of two strings in interval 0 <= x <= 1.
+func jaroWinkler(a, b string) float64 {
+	s1, s2 := []rune(a), []rune(b)
+	if len(s1) == 0 || len(s2) == 0 {
+		return 0
+	}
+
+	window := maxInt(len(s1), len(s2))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	matched1 := make([]bool, len(s1))
+	matched2 := make([]bool, len(s2))
+
+	var matches int
+	for i := range s1 {
+		from, to := maxInt(0, i-window), minInt(len(s2), i+window+1)
+		for j := from; j < to; j++ {
+			if matched2[j] || s1[i] != s2[j] {
+				continue
+			}
+			matched1[i], matched2[j] = true, true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	var transpositions, k int
+	for i := range s1 {
+		if !matched1[i] {
+			continue
+		}
+		for !matched2[k] {
+			k++
+		}
+		if s1[i] != s2[k] {
+			transpositions++
+		}
+		k++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(s1)) + m/float64(len(s2)) + (m-float64(transpositions)/2)/m) / 3
+
+	var prefix int
+	for prefix < minInt(4, minInt(len(s1), len(s2))) && s1[prefix] == s2[prefix] {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
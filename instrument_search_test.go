@@ -0,0 +1,106 @@
+package sdk
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestJaroWinkler(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{a: "martha", b: "marhta", want: 0.961111},
+		{a: "dwayne", b: "duane", want: 0.84},
+		{a: "dixon", b: "dicksonx", want: 0.813333},
+		// odd count of transpositions: matched "abc" against "bca"
+		{a: "abcdef", b: "bcaxyz", want: 0.5},
+		{a: "sber", b: "sber", want: 1},
+		{a: "sber", b: "", want: 0},
+		{a: "abc", b: "xyz", want: 0},
+		{a: "сбер", b: "сбре", want: 0.933333},
+	}
+
+	for _, tt := range tests {
+		if got := jaroWinkler(tt.a, tt.b); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("jaroWinkler(%q, %q) = %f, want %f", tt.a, tt.b, got, tt.want)
+		}
+		if got, reverse := jaroWinkler(tt.a, tt.b), jaroWinkler(tt.b, tt.a); math.Abs(got-reverse) > 1e-9 {
+			t.Errorf("jaroWinkler(%q, %q) = %f isn't symmetric: %f", tt.a, tt.b, got, reverse)
+		}
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	tests := map[string]string{
+		"Apple Inc.":            "apple",
+		"ПАО Сбербанк":          "сбербанк",
+		"Coca-Cola Co":          "coca cola",
+		"  Tesla,  Motors  ":    "tesla motors",
+		"Royal Dutch Shell plc": "royal dutch shell",
+	}
+
+	for name, want := range tests {
+		if got := normalizeName(name); got != want {
+			t.Errorf("normalizeName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestSearchInstruments(t *testing.T) {
+	catalog := newTestCatalog(t)
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []string // FIGIs in rank order
+	}{
+		{name: "exact ticker first", query: "aapl", want: []string{"BBG000B9XRY4", "BBG00ZSPBAPL"}},
+		{name: "exact name before prefix", query: "Apple Inc.", want: []string{"BBG000B9XRY4", "BBG00ZSPBAPL"}},
+		{name: "name prefix", query: "micro", want: []string{"BBG000BPH459"}},
+		{name: "typo", query: "microsfot", want: []string{"BBG000BPH459"}},
+		{name: "cyrillic name", query: "сбер", want: []string{"BBG004730N88"}},
+		{name: "limit", query: "apple", limit: 1, want: []string{"BBG000B9XRY4"}},
+		{name: "limit above count", query: "apple", limit: 10, want: []string{"BBG000B9XRY4", "BBG00ZSPBAPL"}},
+		{name: "no match", query: "qwerty", want: []string{}},
+		{name: "empty query", query: "", want: []string{}},
+		{name: "punctuation only", query: " ., ", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			matches := catalog.SearchInstruments(tt.query, tt.limit)
+
+			got := make([]string, len(matches))
+			for i, m := range matches {
+				got[i] = m.Instrument.FIGI
+				if m.Score < MinSearchScore || m.Score > 1 {
+					t.Errorf("score of %s = %f is out of range", m.Instrument.Ticker, m.Score)
+				}
+				if i > 0 && m.Score > matches[i-1].Score {
+					t.Errorf("%s is ranked below worse match", m.Instrument.Ticker)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SearchInstruments(%q, %d) = %v, want %v", tt.query, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstrumentByISIN(t *testing.T) {
+	catalog := newTestCatalog(t)
+
+	if got := instrumentFIGIs(catalog.InstrumentByISIN("US5949181045")); !reflect.DeepEqual(got, []string{"BBG000BPH459"}) {
+		t.Errorf("InstrumentByISIN = %v", got)
+	}
+	if got := catalog.InstrumentByISIN("US0000000000"); got != nil {
+		t.Errorf("unknown ISIN is found: %v", got)
+	}
+	if got := catalog.InstrumentByISIN(""); got != nil {
+		t.Errorf("empty ISIN is found: %v", got)
+	}
+}
@@ -0,0 +1,153 @@
+package sdk
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxLoggedPayload is max length of raw message or response body written to log.
+const maxLoggedPayload = 512
+
+// redacted replaces secrets in log messages and fields.
+const redacted = "[REDACTED]"
+
+type LogLevel int
+
+const (
+	LogDebug LogLevel = iota
+	LogInfo
+	LogWarn
+	LogError
+)
+
+// bearerPattern matches bearer credentials in messages, e.g. Authorization header dumped by error.
+var bearerPattern = regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9._~+/=-]+`) //nolint:gochecknoglobals // compiled once, read only
+
+type (
+	// Field is key-value pair attached to log record.
+	Field struct {
+		Key   string
+		Value interface{}
+	}
+
+	// StructuredLogger is leveled logger used by RestClient and StreamingClient.
+	// Implementations must be safe for concurrent use.
+	StructuredLogger interface {
+		Log(level LogLevel, msg string, fields ...Field)
+	}
+
+	printfLogger struct {
+		logger Logger
+		level  LogLevel
+	}
+
+	nopLogger struct{}
+
+	// redactingLogger removes token from messages and fields before passing them to next logger.
+	redactingLogger struct {
+		next  StructuredLogger
+		token string
+	}
+)
+
+var (
+	_ StructuredLogger = printfLogger{}
+	_ StructuredLogger = nopLogger{}
+	_ StructuredLogger = redactingLogger{}
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogDebug:
+		return "DEBUG"
+	case LogInfo:
+		return "INFO"
+	case LogWarn:
+		return "WARN"
+	case LogError:
+		return "ERROR"
+	default:
+		return fmt.Sprintf("LEVEL(%d)", int(l))
+	}
+}
+
+// NewPrintfLogger adapts Printf logger, records below level are dropped.
+// Record is written as "LEVEL message key=value key=value".
+func NewPrintfLogger(logger Logger, level LogLevel) StructuredLogger {
+	if logger == nil {
+		return nopLogger{}
+	}
+
+	return printfLogger{logger: logger, level: level}
+}
+
+// Log implements StructuredLogger.
+func (l printfLogger) Log(level LogLevel, msg string, fields ...Field) {
+	if level < l.level {
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(level.String())
+	b.WriteByte(' ')
+	b.WriteString(msg)
+	for _, f := range fields {
+		fmt.Fprintf(&b, " %s=%v", f.Key, f.Value)
+	}
+
+	l.logger.Printf("%s", b.String())
+}
+
+// Log implements StructuredLogger.
+func (nopLogger) Log(LogLevel, string, ...Field) {}
+
+// redactLogger returns logger which never writes token or bearer credentials.
+func redactLogger(logger StructuredLogger, token string) StructuredLogger {
+	if logger == nil {
+		return nopLogger{}
+	}
+	if _, ok := logger.(nopLogger); ok {
+		return logger
+	}
+
+	return redactingLogger{next: logger, token: token}
+}
+
+// Log implements StructuredLogger.
+func (l redactingLogger) Log(level LogLevel, msg string, fields ...Field) {
+	redactedFields := make([]Field, len(fields))
+	for i, f := range fields {
+		switch v := f.Value.(type) {
+		case bool, int, int64, uint64, float64, time.Duration, time.Time:
+		case string:
+			f.Value = l.redact(v)
+		case []byte:
+			f.Value = l.redact(string(v))
+		default:
+			// Errors and structs may contain request headers, so they are logged as redacted text.
+			f.Value = l.redact(fmt.Sprint(v))
+		}
+		redactedFields[i] = f
+	}
+
+	l.next.Log(level, l.redact(msg), redactedFields...)
+}
+
+func (l redactingLogger) redact(s string) string {
+	if l.token != "" {
+		s = strings.ReplaceAll(s, l.token, redacted)
+	}
+
+	return bearerPattern.ReplaceAllString(s, "Bearer "+redacted)
+}
+
+// truncatePayload shortens raw message or body for log.
+func truncatePayload(payload []byte) string {
+	if len(payload) <= maxLoggedPayload {
+		return string(payload)
+	}
+
+	return string(payload[:maxLoggedPayload]) + "...(" + fmt.Sprint(len(payload)) + " bytes)"
+}
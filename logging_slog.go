@@ -0,0 +1,47 @@
+//go:build go1.21
+// +build go1.21
+
+package sdk
+
+import (
+	"context"
+	"log/slog"
+)
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+var _ StructuredLogger = slogLogger{}
+
+// NewSlogLogger adapts slog handler, levels are mapped to slog.LevelDebug, LevelInfo, LevelWarn and LevelError.
+func NewSlogLogger(handler slog.Handler) StructuredLogger {
+	return slogLogger{logger: slog.New(handler)}
+}
+
+// Log implements StructuredLogger.
+func (l slogLogger) Log(level LogLevel, msg string, fields ...Field) {
+	var slogLevel slog.Level
+	switch level {
+	case LogDebug:
+		slogLevel = slog.LevelDebug
+	case LogInfo:
+		slogLevel = slog.LevelInfo
+	case LogWarn:
+		slogLevel = slog.LevelWarn
+	default:
+		slogLevel = slog.LevelError
+	}
+
+	ctx := context.Background()
+	if !l.logger.Enabled(ctx, slogLevel) {
+		return
+	}
+
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, f := range fields {
+		attrs = append(attrs, slog.Any(f.Key, f.Value))
+	}
+
+	l.logger.LogAttrs(ctx, slogLevel, msg, attrs...)
+}
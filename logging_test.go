@@ -0,0 +1,72 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type recordLogger struct {
+	records []string
+}
+
+func (l *recordLogger) Log(level LogLevel, msg string, fields ...Field) {
+	record := level.String() + " " + msg
+	for _, f := range fields {
+		record += fmt.Sprintf(" %s=%v", f.Key, f.Value)
+	}
+	l.records = append(l.records, record)
+}
+
+func TestRedactLogger(t *testing.T) {
+	const token = "t.secret-token_123"
+
+	next := &recordLogger{}
+	logger := redactLogger(next, token)
+
+	logger.Log(LogWarn, "request with "+token+" is failed",
+		Field{Key: "token", Value: token},
+		Field{Key: "body", Value: []byte(`{"token":"` + token + `"}`)},
+		Field{Key: "error", Value: errors.New("dump: Authorization: Bearer other.token-value")},
+		Field{Key: "header", Value: "authorization: bearer AbC+/="},
+		Field{Key: "status", Value: 500},
+	)
+
+	if len(next.records) != 1 {
+		t.Fatalf("got %d records", len(next.records))
+	}
+	record := next.records[0]
+
+	for _, secret := range []string{token, "other.token-value", "AbC+/="} {
+		if strings.Contains(record, secret) {
+			t.Errorf("record contains %q: %s", secret, record)
+		}
+	}
+	want := "WARN request with [REDACTED] is failed token=[REDACTED] body={\"token\":\"[REDACTED]\"} " +
+		"error=dump: Authorization: Bearer [REDACTED] header=authorization: Bearer [REDACTED] status=500"
+	if record != want {
+		t.Errorf("got  %s\nwant %s", record, want)
+	}
+}
+
+func TestRedactLoggerNop(t *testing.T) {
+	if _, ok := redactLogger(nil, "token").(nopLogger); !ok {
+		t.Error("nil logger isn't replaced by nop logger")
+	}
+	if _, ok := redactLogger(nopLogger{}, "token").(nopLogger); !ok {
+		t.Error("nop logger is wrapped")
+	}
+}
+
+func TestTruncatePayload(t *testing.T) {
+	short := []byte("short")
+	if got := truncatePayload(short); got != "short" {
+		t.Errorf("got %q", got)
+	}
+
+	long := []byte(strings.Repeat("x", maxLoggedPayload+10))
+	if got := truncatePayload(long); got != strings.Repeat("x", maxLoggedPayload)+"...(522 bytes)" {
+		t.Errorf("got %q", got)
+	}
+}
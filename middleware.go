@@ -0,0 +1,282 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Defaults of RetryPolicy.
+const (
+	DefaultRetryAttempts   = 3
+	DefaultRetryMinBackoff = 100 * time.Millisecond
+	DefaultRetryMaxBackoff = 5 * time.Second
+)
+
+var ErrInjectedFault = errors.New("injected fault")
+
+type (
+	// RestRequest is request of RestClient passed through middleware chain.
+	// Middleware may change it, e.g. replace Token, before passing to the next handler.
+	RestRequest struct {
+		RequestInfo
+		URL   string
+		Token string
+		// Payload is body of POST request, nil for GET.
+		Payload interface{}
+		// Unmarshal is decoding target of response payload, nil if response isn't decoded.
+		Unmarshal interface{}
+	}
+
+	// RestHandler executes request, returned error is decoded error of API like *APIError or TradingError.
+	RestHandler func(ctx context.Context, req *RestRequest) error
+
+	// Middleware wraps handler, e.g. to retry or log requests.
+	Middleware func(next RestHandler) RestHandler
+
+	// RetryPolicy of RetryMiddleware.
+	RetryPolicy struct {
+		// MaxAttempts is count of attempts including the first one, DefaultRetryAttempts if zero.
+		MaxAttempts int
+		// MinBackoff is delay before the first retry, it doubles every attempt up to MaxBackoff.
+		MinBackoff time.Duration
+		MaxBackoff time.Duration
+		// Retryable reports whether failed request may be retried. By default GET requests are retried
+		// if IsRetryable, POST requests like orders aren't retried, because they may be executed before the error.
+		Retryable func(req *RestRequest, err error) bool
+	}
+
+	// FaultConfig of FaultInjectionMiddleware.
+	FaultConfig struct {
+		// Rate is probability of fault from 0 to 1.
+		Rate float64
+		// Latency is added before every request.
+		Latency time.Duration
+		// Err is returned instead of request execution, retryable APIError with status 503 wrapping ErrInjectedFault by default.
+		Err error
+		// Endpoints limits faults to requests of these endpoints, all requests if empty.
+		Endpoints []string
+		// Rand returns random number in [0, 1), math/rand by default.
+		Rand func() float64
+	}
+
+	// tokenBucket allows rate requests per second with bursts up to burst requests.
+	tokenBucket struct {
+		rate  float64
+		burst float64
+
+		mu     sync.Mutex
+		tokens float64
+		last   time.Time
+	}
+)
+
+// WithMiddleware adds middlewares to chain of rest client. The first middleware is the outermost one,
+// the provider is the terminal handler. Tracer spans cover the whole chain.
+func WithMiddleware(middlewares ...Middleware) BuildOption {
+	return func(client *RestClient) {
+		client.middlewares = append(client.middlewares, middlewares...)
+	}
+}
+
+// RetryMiddleware retries failed requests with exponential backoff and jitter until ctx is done.
+func RetryMiddleware(policy RetryPolicy) Middleware {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = DefaultRetryAttempts
+	}
+	if policy.MinBackoff <= 0 {
+		policy.MinBackoff = DefaultRetryMinBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = DefaultRetryMaxBackoff
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = func(req *RestRequest, err error) bool {
+			return req.Method == http.MethodGet && IsRetryable(err)
+		}
+	}
+
+	return func(next RestHandler) RestHandler {
+		return func(ctx context.Context, req *RestRequest) error {
+			backoff := policy.MinBackoff
+			for attempt := 1; ; attempt++ {
+				err := next(ctx, req)
+				if err == nil || attempt >= policy.MaxAttempts || !policy.Retryable(req, err) {
+					return err
+				}
+
+				// full jitter in [backoff/2, backoff]
+				delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+				timer := time.NewTimer(delay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return err
+				case <-timer.C:
+				}
+
+				if backoff *= 2; backoff > policy.MaxBackoff {
+					backoff = policy.MaxBackoff
+				}
+			}
+		}
+	}
+}
+
+// RateLimitMiddleware delays requests to keep rate requests per second with bursts up to burst requests.
+// Requests waiting for rate limit fail with ctx error when ctx is done. Requests aren't limited if rate <= 0.
+func RateLimitMiddleware(rate float64, burst int) Middleware {
+	if rate <= 0 {
+		return func(next RestHandler) RestHandler { return next }
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	bucket := &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst)}
+
+	return func(next RestHandler) RestHandler {
+		return func(ctx context.Context, req *RestRequest) error {
+			if err := bucket.wait(ctx); err != nil {
+				return err
+			}
+
+			return next(ctx, req)
+		}
+	}
+}
+
+// LoggingMiddleware logs every request at LogDebug level and failed ones at LogWarn with decoded error,
+// token is redacted from all records.
+func LoggingMiddleware(logger StructuredLogger) Middleware {
+	return func(next RestHandler) RestHandler {
+		return func(ctx context.Context, req *RestRequest) error {
+			start := time.Now()
+			err := next(ctx, req)
+
+			level := LogDebug
+			fields := []Field{
+				{Key: "endpoint", Value: req.Endpoint},
+				{Key: "method", Value: req.Method},
+				{Key: "path", Value: req.Path},
+				{Key: "duration", Value: time.Since(start)},
+			}
+			if req.AccountID != "" {
+				fields = append(fields, Field{Key: "account_id", Value: req.AccountID})
+			}
+			if req.FIGI != "" {
+				fields = append(fields, Field{Key: "figi", Value: req.FIGI})
+			}
+			if err != nil {
+				level = LogWarn
+				fields = append(fields, Field{Key: "error", Value: err})
+			}
+			redactLogger(logger, req.Token).Log(level, "Request is handled", fields...)
+
+			return err
+		}
+	}
+}
+
+// FaultInjectionMiddleware adds latency and fails requests with probability, use it to test error handling.
+func FaultInjectionMiddleware(cfg FaultConfig) Middleware {
+	if cfg.Rand == nil {
+		cfg.Rand = rand.Float64
+	}
+	endpoints := make(map[string]bool, len(cfg.Endpoints))
+	for _, e := range cfg.Endpoints {
+		endpoints[e] = true
+	}
+
+	return func(next RestHandler) RestHandler {
+		return func(ctx context.Context, req *RestRequest) error {
+			if len(endpoints) > 0 && !endpoints[req.Endpoint] {
+				return next(ctx, req)
+			}
+
+			if cfg.Latency > 0 {
+				timer := time.NewTimer(cfg.Latency)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-timer.C:
+				}
+			}
+
+			if cfg.Rand() >= cfg.Rate {
+				return next(ctx, req)
+			}
+			if cfg.Err != nil {
+				return cfg.Err
+			}
+
+			return &APIError{
+				StatusCode: http.StatusServiceUnavailable,
+				Method:     req.Method,
+				Path:       req.Path,
+				Err:        ErrInjectedFault,
+			}
+		}
+	}
+}
+
+// handle executes request by the provider, it is the terminal handler of middleware chain.
+func (c *RestClient) handle(ctx context.Context, req *RestRequest) error {
+	if req.Method == http.MethodGet {
+		return c.provider.Get(ctx, req.URL, req.Token, req.Unmarshal)
+	}
+
+	return c.provider.Post(ctx, req.URL, req.Token, req.Payload, req.Unmarshal)
+}
+
+// chain returns handler of all middlewares around the terminal handler.
+func (c *RestClient) chain() RestHandler {
+	handler := RestHandler(c.handle)
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		handler = c.middlewares[i](handler)
+	}
+
+	return handler
+}
+
+// wait takes token from the bucket waiting for it if bucket is empty.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		delay := b.take(time.Now())
+		if delay == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// take takes token and returns 0 or returns time until token is available.
+func (b *tokenBucket) take(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
@@ -0,0 +1,134 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// timeoutServer returns server which responds slower than client timeout and counter of received requests.
+func timeoutServer(t *testing.T) (*RestClient, *int32) {
+	t.Helper()
+
+	var calls int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(func() {
+		close(release)
+		srv.Close()
+	})
+
+	client := NewRestClient("token",
+		WithURL(srv.URL),
+		WithProvider(&defaultHTTP{client: &http.Client{Timeout: 50 * time.Millisecond}, logger: nopLogger{}}),
+		WithMiddleware(RetryMiddleware(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond})),
+	)
+
+	return client, &calls
+}
+
+func TestRetryMiddlewareTimedOutPost(t *testing.T) {
+	client, calls := timeoutServer(t)
+
+	_, err := client.MarketOrder(context.Background(), DefaultAccount, "BBG000B9XRY4", 1, BUY)
+
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Fatalf("want timeout error, got %v", err)
+	}
+	if n := atomic.LoadInt32(calls); n != 1 {
+		t.Fatalf("timed out POST is sent %d times, want 1", n)
+	}
+}
+
+func TestRetryMiddlewareTimedOutGet(t *testing.T) {
+	client, calls := timeoutServer(t)
+
+	if _, err := client.InstrumentByFIGI(context.Background(), "BBG000B9XRY4"); err == nil {
+		t.Fatal("want error")
+	}
+	if n := atomic.LoadInt32(calls); n != 3 {
+		t.Fatalf("timed out GET is sent %d times, want 3", n)
+	}
+}
+
+func TestRetryMiddlewareServerError(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"payload":{"figi":"BBG000B9XRY4","ticker":"AAPL"}}`))
+	}))
+	defer srv.Close()
+
+	client := NewRestClient("token", WithURL(srv.URL),
+		WithMiddleware(RetryMiddleware(RetryPolicy{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond})))
+
+	instrument, err := client.InstrumentByFIGI(context.Background(), "BBG000B9XRY4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if instrument.Ticker != "AAPL" || atomic.LoadInt32(&calls) != 3 {
+		t.Fatalf("got %+v after %d calls", instrument, calls)
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(`{"payload":{}}`))
+	}))
+	defer srv.Close()
+
+	client := NewRestClient("token", WithURL(srv.URL), WithMiddleware(RateLimitMiddleware(20, 2)))
+
+	start := time.Now()
+	errs := make(chan error, 6)
+	for i := 0; i < 6; i++ {
+		go func() {
+			_, err := client.Orderbook(context.Background(), 1, "BBG000B9XRY4")
+			errs <- err
+		}()
+	}
+	for i := 0; i < 6; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 2 requests of burst are sent at once, the rest 4 are sent every 50ms.
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Fatalf("6 requests are sent in %s", elapsed)
+	}
+	if n := atomic.LoadInt32(&calls); n != 6 {
+		t.Fatalf("got %d requests", n)
+	}
+}
+
+func TestRateLimitMiddlewareContext(t *testing.T) {
+	handler := RateLimitMiddleware(1, 1)(func(context.Context, *RestRequest) error { return nil })
+
+	if err := handler(context.Background(), &RestRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := handler(ctx, &RestRequest{}); err != context.DeadlineExceeded {
+		t.Fatalf("want deadline exceeded, got %v", err)
+	}
+}
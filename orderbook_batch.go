@@ -0,0 +1,158 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultBatchConcurrency is default count of concurrent requests of batch methods.
+const DefaultBatchConcurrency = 4
+
+var ErrNoPrice = errors.New("neither last nor close price")
+
+// BatchError contains errors of failed FIGIs of batch request.
+type BatchError struct {
+	Errors map[string]error
+}
+
+// Error implements error.
+func (e *BatchError) Error() string {
+	figis := make([]string, 0, len(e.Errors))
+	for figi := range e.Errors {
+		figis = append(figis, figi)
+	}
+	sort.Strings(figis)
+
+	msgs := make([]string, 0, len(figis))
+	for _, figi := range figis {
+		msgs = append(msgs, figi+": "+e.Errors[figi].Error())
+	}
+
+	return fmt.Sprintf("%d of batch requests failed: %s", len(figis), strings.Join(msgs, "; "))
+}
+
+// Is reports whether error of any FIGI is target.
+func (e *BatchError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OrderbookBatch requests orderbooks of figis with at most concurrency (DefaultBatchConcurrency if <= 0)
+// requests at once, middlewares of the client like rate limit are applied to every request.
+// Orderbooks are returned by FIGI, errors of failed FIGIs are returned as *BatchError with the rest orderbooks.
+func (c *RestClient) OrderbookBatch(ctx context.Context, depth int, figis []string, concurrency int) (map[string]RestOrderBook, error) {
+	if depth < 1 || depth > MaxOrderbookDepth {
+		return nil, ErrDepth
+	}
+
+	var mu sync.Mutex
+	books := make(map[string]RestOrderBook, len(figis))
+	err := batch(ctx, figis, concurrency, func(ctx context.Context, figi string) error {
+		book, err := c.Orderbook(ctx, depth, figi)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		books[figi] = book
+		mu.Unlock()
+
+		return nil
+	})
+
+	return books, err
+}
+
+// LastPrices returns last prices of figis by orderbooks of depth 1, close price is used if there is no last one.
+// Errors of failed FIGIs are returned as *BatchError with the rest prices, see OrderbookBatch.
+func (c *RestClient) LastPrices(ctx context.Context, figis []string, concurrency int) (map[string]float64, error) {
+	books, err := c.OrderbookBatch(ctx, 1, figis, concurrency)
+
+	var batchErr *BatchError
+	if err != nil && !errors.As(err, &batchErr) {
+		return nil, err
+	}
+
+	prices := make(map[string]float64, len(books))
+	for figi, book := range books {
+		switch {
+		case book.LastPrice > 0:
+			prices[figi] = book.LastPrice
+		case book.ClosePrice > 0:
+			prices[figi] = book.ClosePrice
+		default:
+			if batchErr == nil {
+				batchErr = &BatchError{Errors: make(map[string]error)}
+			}
+			batchErr.Errors[figi] = ErrNoPrice
+		}
+	}
+
+	if batchErr != nil {
+		return prices, batchErr
+	}
+
+	return prices, nil
+}
+
+// batch calls fn for unique figis with bounded concurrency, FIGIs not started before ctx is done fail with ctx error.
+func batch(ctx context.Context, figis []string, concurrency int, fn func(ctx context.Context, figi string) error) error {
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+
+	var (
+		mu   sync.Mutex
+		errs = make(map[string]error)
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, concurrency)
+		seen = make(map[string]bool, len(figis))
+	)
+	fail := func(figi string, err error) {
+		mu.Lock()
+		errs[figi] = err
+		mu.Unlock()
+	}
+
+	for _, figi := range figis {
+		if seen[figi] {
+			continue
+		}
+		seen[figi] = true
+
+		select {
+		case <-ctx.Done():
+			fail(figi, ctx.Err())
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(figi string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := fn(ctx, figi); err != nil {
+				fail(figi, err)
+			}
+		}(figi)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return &BatchError{Errors: errs}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOrderbookBatchConcurrent(t *testing.T) {
+	const concurrency = 3
+
+	var inflight, maxInflight, calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		n := atomic.AddInt32(&inflight, 1)
+		defer atomic.AddInt32(&inflight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInflight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInflight, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		figi := r.URL.Query().Get("figi")
+		switch figi {
+		case testRejectedFIGI:
+			w.WriteHeader(http.StatusNotFound)
+		case "BBG000000002":
+			_, _ = fmt.Fprintf(w, `{"payload":{"figi":%q,"closePrice":5}}`, figi)
+		default:
+			_, _ = fmt.Fprintf(w, `{"payload":{"figi":%q,"lastPrice":10,"closePrice":5}}`, figi)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewRestClient("token", WithURL(srv.URL))
+
+	figis := []string{testRejectedFIGI, "BBG000000002"}
+	for i := 0; i < 10; i++ {
+		figis = append(figis, testPoolFIGI(i+10))
+	}
+	figis = append(figis, figis[2], figis[3])
+
+	prices, err := client.LastPrices(context.Background(), figis, concurrency)
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || len(batchErr.Errors) != 1 || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("want batch error of rejected figi, got %v", err)
+	}
+	if len(prices) != 11 || prices["BBG000000002"] != 5 || prices[figis[2]] != 10 {
+		t.Fatalf("got prices %v", prices)
+	}
+	if n := atomic.LoadInt32(&calls); n != 12 {
+		t.Fatalf("server received %d requests, duplicates should be requested once", n)
+	}
+	if n := atomic.LoadInt32(&maxInflight); n > concurrency {
+		t.Fatalf("%d requests in flight, want at most %d", n, concurrency)
+	}
+}
+
+func TestOrderbookBatchContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	client := NewRestClient("token", WithURL(srv.URL))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	books, err := client.OrderbookBatch(ctx, 1, []string{testFIGI, testFIGIMOEX, testPoolFIGI(0)}, 1)
+	if len(books) != 0 || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, %v", books, err)
+	}
+}
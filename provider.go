@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"time"
 )
 
 var _ Provider = &defaultHTTP{}
@@ -20,6 +22,7 @@ type (
 
 	defaultHTTP struct {
 		client *http.Client
+		logger StructuredLogger
 	}
 )
 
@@ -42,14 +45,13 @@ func (c *defaultHTTP) Post(ctx context.Context, url string, token string, payloa
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+token)
 
-	resp, err := c.do(req)
+	respBody, err := c.do(req)
 	if err != nil {
 		return fmt.Errorf("provider do: %w", err)
 	}
-	defer resp.Body.Close()
 
 	if unmarshal != nil {
-		err = json.NewDecoder(resp.Body).Decode(unmarshal)
+		err = json.Unmarshal(respBody, unmarshal)
 		if err != nil {
 			return fmt.Errorf("decode json: %w", err)
 		}
@@ -68,13 +70,12 @@ func (c *defaultHTTP) Get(ctx context.Context, url string, token string, unmarsh
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+token)
 
-	resp, err := c.do(req)
+	respBody, err := c.do(req)
 	if err != nil {
 		return fmt.Errorf("provider do: %w", err)
 	}
-	defer resp.Body.Close()
 
-	err = json.NewDecoder(resp.Body).Decode(unmarshal)
+	err = json.Unmarshal(respBody, unmarshal)
 	if err != nil {
 		return fmt.Errorf("decode json: %w", err)
 	}
@@ -82,25 +83,65 @@ func (c *defaultHTTP) Get(ctx context.Context, url string, token string, unmarsh
 	return nil
 }
 
-func (c *defaultHTTP) do(req *http.Request) (*http.Response, error) {
+// do sends request and returns body of successful response.
+func (c *defaultHTTP) do(req *http.Request) ([]byte, error) {
+	start := time.Now()
+
 	resp, err := c.client.Do(req)
 	if err != nil {
+		c.logger.Log(LogError, "Request is failed",
+			Field{Key: "method", Value: req.Method},
+			Field{Key: "path", Value: req.URL.Path},
+			Field{Key: "duration", Value: time.Since(start)},
+			Field{Key: "error", Value: err},
+		)
 		return nil, fmt.Errorf("provider client do: %w", err)
 	}
+	defer resp.Body.Close()
 
-	switch resp.StatusCode {
-	case http.StatusOK:
-	case http.StatusNotFound:
-		return nil, ErrNotFound
-	default:
-		tradingError := TradingError{}
-		err := json.NewDecoder(resp.Body).Decode(&tradingError)
-		if err != nil {
-			return nil, fmt.Errorf("json decode error: %w", err)
-		}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read body: %w", err)
+	}
+
+	var envelope struct {
+		TrackingID string `json:"trackingId"`
+	}
+	_ = json.Unmarshal(body, &envelope)
+
+	level := LogDebug
+	fields := []Field{
+		{Key: "method", Value: req.Method},
+		{Key: "path", Value: req.URL.Path},
+		{Key: "duration", Value: time.Since(start)},
+		{Key: "status", Value: resp.StatusCode},
+		{Key: "tracking_id", Value: envelope.TrackingID},
+	}
+	if resp.StatusCode != http.StatusOK {
+		level = LogWarn
+		fields = append(fields, Field{Key: "body", Value: truncatePayload(body)})
+	}
+	c.logger.Log(level, "Request is done", fields...)
+
+	if resp.StatusCode == http.StatusOK {
+		return body, nil
+	}
+
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		TrackingID: envelope.TrackingID,
+		Body:       truncatePayload(body),
+	}
 
-		return nil, tradingError
+	tradingError := TradingError{}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		apiErr.Err = ErrNotFound
+	case json.Unmarshal(body, &tradingError) == nil && (tradingError.Payload.Code != "" || tradingError.Payload.Message != ""):
+		apiErr.Err = tradingError.withHint()
 	}
 
-	return resp, nil
+	return nil, apiErr
 }
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -29,6 +30,11 @@ type (
 		provider Provider
 		token    string
 		url      string
+		logger   StructuredLogger
+		tracer   Tracer
+
+		middlewares []Middleware
+		handler     RestHandler
 	}
 
 	// BuildOption build options for rest client.
@@ -42,10 +48,18 @@ func WithProvider(p Provider) BuildOption {
 	}
 }
 
-// WithURL build rest client by custom api url.
+// WithURL build rest client by custom api url, trailing slashes are trimmed.
 func WithURL(url string) BuildOption {
 	return func(client *RestClient) {
-		client.url = url
+		client.url = strings.TrimRight(url, "/")
+	}
+}
+
+// WithLogger build rest client with logger of requests, token is redacted from all records.
+// Requests are logged by default provider only.
+func WithLogger(logger StructuredLogger) BuildOption {
+	return func(client *RestClient) {
+		client.logger = logger
 	}
 }
 
@@ -58,14 +72,22 @@ func NewRestClient(token string, options ...BuildOption) *RestClient {
 				Timeout:   MaxTimeout,
 			},
 		},
-		token: token,
-		url:   RestAPIURL,
+		token:  token,
+		url:    RestAPIURL,
+		logger: nopLogger{},
+		tracer: nopTracer{},
 	}
 
 	for i := range options {
 		options[i](client)
 	}
 
+	client.logger = redactLogger(client.logger, token)
+	if p, ok := client.provider.(*defaultHTTP); ok {
+		p.logger = client.logger
+	}
+	client.handler = client.chain()
+
 	return client
 }
 
@@ -78,11 +100,12 @@ func NewRestClientCustom(token, apiURL string) *RestClient {
 // InstrumentByFIGI see docs https://tinkoffcreditsystems.github.io/invest-openapi/swagger-ui/#/market/get_market_search_by_figi.
 func (c *RestClient) InstrumentByFIGI(ctx context.Context, figi string) (Instrument, error) {
 	var response struct {
+		responseEnvelope
 		Payload Instrument `json:"payload"`
 	}
 
 	path := c.url + "/market/search/by-figi?figi=" + figi
-	err := c.provider.Get(ctx, path, c.token, &response)
+	err := c.get(ctx, RequestInfo{Endpoint: "InstrumentByFIGI", FIGI: figi}, path, &response)
 	if err != nil {
 		return Instrument{}, fmt.Errorf("provider get: %w", err)
 	}
@@ -93,6 +116,7 @@ func (c *RestClient) InstrumentByFIGI(ctx context.Context, figi string) (Instrum
 // InstrumentByTicker see docs https://tinkoffcreditsystems.github.io/invest-openapi/swagger-ui/#/market/get_market_search_by_ticker.
 func (c *RestClient) InstrumentByTicker(ctx context.Context, ticker string) ([]Instrument, error) {
 	var response struct {
+		responseEnvelope
 		Payload struct {
 			Instruments []Instrument `json:"instruments"`
 		} `json:"payload"`
@@ -100,7 +124,7 @@ func (c *RestClient) InstrumentByTicker(ctx context.Context, ticker string) ([]I
 
 	path := c.url + "/market/search/by-ticker?ticker=" + ticker
 
-	err := c.provider.Get(ctx, path, c.token, &response)
+	err := c.get(ctx, RequestInfo{Endpoint: "InstrumentByTicker"}, path, &response)
 	if err != nil {
 		return nil, fmt.Errorf("provider get: %w", err)
 	}
@@ -111,6 +135,7 @@ func (c *RestClient) InstrumentByTicker(ctx context.Context, ticker string) ([]I
 // Currencies see docs https://tinkoffcreditsystems.github.io/invest-openapi/swagger-ui/#/market/get_market_currencies.
 func (c *RestClient) Currencies(ctx context.Context) ([]Instrument, error) {
 	var response struct {
+		responseEnvelope
 		Payload struct {
 			Instruments []Instrument `json:"instruments"`
 		} `json:"payload"`
@@ -118,7 +143,7 @@ func (c *RestClient) Currencies(ctx context.Context) ([]Instrument, error) {
 
 	path := c.url + "/market/currencies"
 
-	err := c.provider.Get(ctx, path, c.token, &response)
+	err := c.get(ctx, RequestInfo{Endpoint: "Currencies"}, path, &response)
 	if err != nil {
 		return nil, fmt.Errorf("provider get: %w", err)
 	}
@@ -129,6 +154,7 @@ func (c *RestClient) Currencies(ctx context.Context) ([]Instrument, error) {
 // ETFs see docs https://tinkoffcreditsystems.github.io/invest-openapi/swagger-ui/#/market/get_market_etfs.
 func (c *RestClient) ETFs(ctx context.Context) ([]Instrument, error) {
 	var response struct {
+		responseEnvelope
 		Payload struct {
 			Instruments []Instrument `json:"instruments"`
 		} `json:"payload"`
@@ -136,7 +162,7 @@ func (c *RestClient) ETFs(ctx context.Context) ([]Instrument, error) {
 
 	path := c.url + "/market/etfs"
 
-	err := c.provider.Get(ctx, path, c.token, &response)
+	err := c.get(ctx, RequestInfo{Endpoint: "ETFs"}, path, &response)
 	if err != nil {
 		return nil, fmt.Errorf("provider get: %w", err)
 	}
@@ -147,6 +173,7 @@ func (c *RestClient) ETFs(ctx context.Context) ([]Instrument, error) {
 // Bonds see docs https://tinkoffcreditsystems.github.io/invest-openapi/swagger-ui/#/market/get_market_bonds.
 func (c *RestClient) Bonds(ctx context.Context) ([]Instrument, error) {
 	var response struct {
+		responseEnvelope
 		Payload struct {
 			Instruments []Instrument `json:"instruments"`
 		} `json:"payload"`
@@ -154,7 +181,7 @@ func (c *RestClient) Bonds(ctx context.Context) ([]Instrument, error) {
 
 	path := c.url + "/market/bonds"
 
-	err := c.provider.Get(ctx, path, c.token, &response)
+	err := c.get(ctx, RequestInfo{Endpoint: "Bonds"}, path, &response)
 	if err != nil {
 		return nil, fmt.Errorf("provider get: %w", err)
 	}
@@ -165,6 +192,7 @@ func (c *RestClient) Bonds(ctx context.Context) ([]Instrument, error) {
 // Stocks see docs https://tinkoffcreditsystems.github.io/invest-openapi/swagger-ui/#/market/get_market_stocks.
 func (c *RestClient) Stocks(ctx context.Context) ([]Instrument, error) {
 	var response struct {
+		responseEnvelope
 		Payload struct {
 			Instruments []Instrument `json:"instruments"`
 		} `json:"payload"`
@@ -172,7 +200,7 @@ func (c *RestClient) Stocks(ctx context.Context) ([]Instrument, error) {
 
 	path := c.url + "/market/stocks"
 
-	err := c.provider.Get(ctx, path, c.token, &response)
+	err := c.get(ctx, RequestInfo{Endpoint: "Stocks"}, path, &response)
 	if err != nil {
 		return nil, fmt.Errorf("provider get: %w", err)
 	}
@@ -183,6 +211,7 @@ func (c *RestClient) Stocks(ctx context.Context) ([]Instrument, error) {
 // Operations see docs https://tinkoffcreditsystems.github.io/invest-openapi/swagger-ui/#/operations/get_operations.
 func (c *RestClient) Operations(ctx context.Context, accountID string, from, to time.Time, figi string) ([]Operation, error) {
 	var response struct {
+		responseEnvelope
 		Payload struct {
 			Operations []Operation `json:"operations"`
 		} `json:"payload"`
@@ -201,7 +230,7 @@ func (c *RestClient) Operations(ctx context.Context, accountID string, from, to
 
 	path := c.url + "/operations?" + q.Encode()
 
-	err := c.provider.Get(ctx, path, c.token, &response)
+	err := c.get(ctx, RequestInfo{Endpoint: "Operations", AccountID: accountID, FIGI: figi}, path, &response)
 	if err != nil {
 		return nil, fmt.Errorf("provider get: %w", err)
 	}
@@ -230,6 +259,7 @@ func (c *RestClient) Portfolio(ctx context.Context, accountID string) (Portfolio
 // PositionsPortfolio see docs https://tinkoffcreditsystems.github.io/invest-openapi/swagger-ui/#/portfolio/get_portfolio.
 func (c *RestClient) PositionsPortfolio(ctx context.Context, accountID string) ([]PositionBalance, error) {
 	var response struct {
+		responseEnvelope
 		Payload struct {
 			Positions []PositionBalance `json:"positions"`
 		} `json:"payload"`
@@ -241,7 +271,7 @@ func (c *RestClient) PositionsPortfolio(ctx context.Context, accountID string) (
 		path += "?brokerAccountId=" + accountID
 	}
 
-	err := c.provider.Get(ctx, path, c.token, &response)
+	err := c.get(ctx, RequestInfo{Endpoint: "PositionsPortfolio", AccountID: accountID}, path, &response)
 	if err != nil {
 		return nil, fmt.Errorf("provider get: %w", err)
 	}
@@ -252,6 +282,7 @@ func (c *RestClient) PositionsPortfolio(ctx context.Context, accountID string) (
 // CurrenciesPortfolio see docs https://tinkoffcreditsystems.github.io/invest-openapi/swagger-ui/#/portfolio/get_portfolio_currencies.
 func (c *RestClient) CurrenciesPortfolio(ctx context.Context, accountID string) ([]CurrencyBalance, error) {
 	var response struct {
+		responseEnvelope
 		Payload struct {
 			Currencies []CurrencyBalance `json:"currencies"`
 		} `json:"payload"`
@@ -263,7 +294,7 @@ func (c *RestClient) CurrenciesPortfolio(ctx context.Context, accountID string)
 		path += "?brokerAccountId=" + accountID
 	}
 
-	err := c.provider.Get(ctx, path, c.token, &response)
+	err := c.get(ctx, RequestInfo{Endpoint: "CurrenciesPortfolio", AccountID: accountID}, path, &response)
 	if err != nil {
 		return nil, fmt.Errorf("provider get: %w", err)
 	}
@@ -278,7 +309,7 @@ func (c *RestClient) OrderCancel(ctx context.Context, accountID, id string) erro
 		path += "&brokerAccountId=" + accountID
 	}
 
-	err := c.provider.Post(ctx, path, c.token, nil, nil)
+	err := c.post(ctx, RequestInfo{Endpoint: "OrderCancel", AccountID: accountID}, path, nil, nil)
 	if err != nil {
 		return fmt.Errorf("provider post: %w", err)
 	}
@@ -295,6 +326,7 @@ func (c *RestClient) LimitOrder(
 	price float64,
 ) (PlacedOrder, error) {
 	var response struct {
+		responseEnvelope
 		Payload PlacedOrder `json:"payload"`
 	}
 
@@ -310,7 +342,7 @@ func (c *RestClient) LimitOrder(
 		Price     float64       `json:"price"`
 	}{Lots: lots, Operation: operation, Price: price}
 
-	err := c.provider.Post(ctx, path, c.token, payload, &response)
+	err := c.post(ctx, RequestInfo{Endpoint: "LimitOrder", AccountID: accountID, FIGI: figi}, path, payload, &response)
 	if err != nil {
 		return PlacedOrder{}, fmt.Errorf("provider post: %w", err)
 	}
@@ -321,6 +353,7 @@ func (c *RestClient) LimitOrder(
 // MarketOrder see docs https://tinkoffcreditsystems.github.io/invest-openapi/swagger-ui/#/orders/post_orders_market_order.
 func (c *RestClient) MarketOrder(ctx context.Context, accountID, figi string, lots int, operation OperationType) (PlacedOrder, error) {
 	var response struct {
+		responseEnvelope
 		Payload PlacedOrder `json:"payload"`
 	}
 
@@ -335,7 +368,7 @@ func (c *RestClient) MarketOrder(ctx context.Context, accountID, figi string, lo
 		Operation OperationType `json:"operation"`
 	}{Lots: lots, Operation: operation}
 
-	err := c.provider.Post(ctx, path, c.token, payload, &response)
+	err := c.post(ctx, RequestInfo{Endpoint: "MarketOrder", AccountID: accountID, FIGI: figi}, path, payload, &response)
 	if err != nil {
 		return PlacedOrder{}, fmt.Errorf("provider post: %w", err)
 	}
@@ -346,6 +379,7 @@ func (c *RestClient) MarketOrder(ctx context.Context, accountID, figi string, lo
 // Orders see docs https://tinkoffcreditsystems.github.io/invest-openapi/swagger-ui/#/orders/get_orders.
 func (c *RestClient) Orders(ctx context.Context, accountID string) ([]Order, error) {
 	var response struct {
+		responseEnvelope
 		Payload []Order `json:"payload"`
 	}
 
@@ -355,7 +389,7 @@ func (c *RestClient) Orders(ctx context.Context, accountID string) ([]Order, err
 		path += "?brokerAccountId=" + accountID
 	}
 
-	err := c.provider.Get(ctx, path, c.token, &response)
+	err := c.get(ctx, RequestInfo{Endpoint: "Orders", AccountID: accountID}, path, &response)
 	if err != nil {
 		return nil, fmt.Errorf("provider get: %w", err)
 	}
@@ -366,6 +400,7 @@ func (c *RestClient) Orders(ctx context.Context, accountID string) ([]Order, err
 // Candles see docs https://tinkoffcreditsystems.github.io/invest-openapi/swagger-ui/#/market/get_market_candles.
 func (c *RestClient) Candles(ctx context.Context, from, to time.Time, interval CandleInterval, figi string) ([]Candle, error) {
 	var response struct {
+		responseEnvelope
 		Payload struct {
 			FIGI     string         `json:"figi"`
 			Interval CandleInterval `json:"interval"`
@@ -381,7 +416,7 @@ func (c *RestClient) Candles(ctx context.Context, from, to time.Time, interval C
 	}
 	path := c.url + "/market/candles?" + q.Encode()
 
-	err := c.provider.Get(ctx, path, c.token, &response)
+	err := c.get(ctx, RequestInfo{Endpoint: "Candles", FIGI: figi}, path, &response)
 	if err != nil {
 		return nil, fmt.Errorf("provider get: %w", err)
 	}
@@ -392,6 +427,7 @@ func (c *RestClient) Candles(ctx context.Context, from, to time.Time, interval C
 // Orderbook see docs https://tinkoffcreditsystems.github.io/invest-openapi/swagger-ui/#/market/get_market_orderbook.
 func (c *RestClient) Orderbook(ctx context.Context, depth int, figi string) (RestOrderBook, error) {
 	var response struct {
+		responseEnvelope
 		Payload RestOrderBook `json:"payload"`
 	}
 
@@ -405,7 +441,7 @@ func (c *RestClient) Orderbook(ctx context.Context, depth int, figi string) (Res
 	}
 	path := c.url + "/market/orderbook?" + q.Encode()
 
-	err := c.provider.Get(ctx, path, c.token, &response)
+	err := c.get(ctx, RequestInfo{Endpoint: "Orderbook", FIGI: figi}, path, &response)
 	if err != nil {
 		return RestOrderBook{}, fmt.Errorf("provider get: %w", err)
 	}
@@ -416,6 +452,7 @@ func (c *RestClient) Orderbook(ctx context.Context, depth int, figi string) (Res
 // Accounts see docs https://tinkoffcreditsystems.github.io/invest-openapi/swagger-ui/#/user/get_user_accounts.
 func (c *RestClient) Accounts(ctx context.Context) ([]Account, error) {
 	var response struct {
+		responseEnvelope
 		Payload struct {
 			Accounts []Account `json:"accounts"`
 		} `json:"payload"`
@@ -423,7 +460,7 @@ func (c *RestClient) Accounts(ctx context.Context) ([]Account, error) {
 
 	path := c.url + "/user/accounts"
 
-	err := c.provider.Get(ctx, path, c.token, &response)
+	err := c.get(ctx, RequestInfo{Endpoint: "Accounts"}, path, &response)
 	if err != nil {
 		return nil, fmt.Errorf("provider get: %w", err)
 	}
@@ -5,24 +5,33 @@ import (
 	"fmt"
 )
 
+// SandboxRestAPIURL contains sandbox api url for tinkoff invest api.
+// All methods of RestClient are available by it, sandbox methods have /sandbox prefix, e.g. SandboxRestAPIURL+"/sandbox/register".
+const SandboxRestAPIURL = RestAPIURL + "/sandbox"
+
 // SandboxRestClient rest client for sandbox tinkoff invest.
 type SandboxRestClient struct {
 	*RestClient
 }
 
-// NewSandboxRestClient returns new SandboxRestClient by token.
-func NewSandboxRestClient(token string) *SandboxRestClient {
-	return &SandboxRestClient{RestClient: NewRestClient(token, WithURL(RestAPIURL+"/sandbox"))}
+// NewSandboxRestClient returns new SandboxRestClient by token and options of NewRestClient.
+// URL is SandboxRestAPIURL by default, custom url passed by WithURL should be sandbox root like SandboxRestAPIURL.
+func NewSandboxRestClient(token string, options ...BuildOption) *SandboxRestClient {
+	options = append([]BuildOption{WithURL(SandboxRestAPIURL)}, options...)
+
+	return &SandboxRestClient{RestClient: NewRestClient(token, options...)}
 }
 
-// NewSandboxRestClientCustom returns new custom SandboxRestClient by token and api url.
+// NewSandboxRestClientCustom returns new custom SandboxRestClient by token and sandbox root api url.
+// Deprecated: have to use NewSandboxRestClient with WithURL option.
 func NewSandboxRestClientCustom(token, apiURL string) *SandboxRestClient {
-	return &SandboxRestClient{RestClient: NewRestClient(token, WithURL(apiURL))}
+	return NewSandboxRestClient(token, WithURL(apiURL))
 }
 
 // Register see docs https://tinkoffcreditsystems.github.io/invest-openapi/swagger-ui/#/sandbox/post_sandbox_register.
 func (c *SandboxRestClient) Register(ctx context.Context, accountType AccountType) (Account, error) {
 	var response struct {
+		responseEnvelope
 		Payload Account `json:"payload"`
 	}
 
@@ -32,7 +41,7 @@ func (c *SandboxRestClient) Register(ctx context.Context, accountType AccountTyp
 		AccountType AccountType `json:"brokerAccountType"`
 	}{AccountType: accountType}
 
-	err := c.provider.Post(ctx, path, c.token, payload, &response)
+	err := c.post(ctx, RequestInfo{Endpoint: "Register"}, path, payload, &response)
 	if err != nil {
 		return Account{}, fmt.Errorf("provider post: %w", err)
 	}
@@ -48,7 +57,7 @@ func (c *SandboxRestClient) Clear(ctx context.Context, accountID string) error {
 		path += "?brokerAccountId=" + accountID
 	}
 
-	err := c.provider.Post(ctx, path, c.token, nil, nil)
+	err := c.post(ctx, RequestInfo{Endpoint: "Clear", AccountID: accountID}, path, nil, nil)
 	if err != nil {
 		return fmt.Errorf("provider post: %w", err)
 	}
@@ -64,7 +73,7 @@ func (c *SandboxRestClient) Remove(ctx context.Context, accountID string) error
 		path += "?brokerAccountId=" + accountID
 	}
 
-	err := c.provider.Post(ctx, path, c.token, nil, nil)
+	err := c.post(ctx, RequestInfo{Endpoint: "Remove", AccountID: accountID}, path, nil, nil)
 	if err != nil {
 		return fmt.Errorf("provider post: %w", err)
 	}
@@ -86,7 +95,7 @@ func (c *SandboxRestClient) SetCurrencyBalance(ctx context.Context, accountID st
 		payload.AccountID = accountID
 	}
 
-	err := c.provider.Post(ctx, path, c.token, payload, nil)
+	err := c.post(ctx, RequestInfo{Endpoint: "SetCurrencyBalance", AccountID: accountID}, path, payload, nil)
 	if err != nil {
 		return fmt.Errorf("provider post: %w", err)
 	}
@@ -108,7 +117,7 @@ func (c *SandboxRestClient) SetPositionsBalance(ctx context.Context, accountID,
 		payload.AccountID = accountID
 	}
 
-	err := c.provider.Post(ctx, path, c.token, payload, nil)
+	err := c.post(ctx, RequestInfo{Endpoint: "SetPositionsBalance", AccountID: accountID, FIGI: figi}, path, payload, nil)
 	if err != nil {
 		return fmt.Errorf("provider post: %w", err)
 	}
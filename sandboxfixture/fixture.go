@@ -0,0 +1,307 @@
+// Package sandboxfixture declares sandbox accounts state in YAML and applies it by SandboxRestClient in tests.
+package sandboxfixture
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	sdk "github.com/Tinkoff/invest-openapi-go-sdk"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	ErrInvalidFixture  = errors.New("invalid sandbox fixture")
+	ErrAmbiguousTicker = errors.New("ticker matches several instruments, use figi")
+)
+
+var _ Client = &sdk.SandboxRestClient{}
+
+type (
+	// Fixture declares sandbox accounts state, e.g.:
+	//
+	//	accounts:
+	//	  - name: main
+	//	    type: Tinkoff
+	//	    currencies:
+	//	      RUB: 100000
+	//	      USD: 1000
+	//	    positions:
+	//	      - ticker: SBER
+	//	        balance: 10
+	//	      - figi: BBG000B9XRY4
+	//	        balance: 5
+	Fixture struct {
+		Accounts []Account `yaml:"accounts"`
+	}
+
+	// Account declares balances of account, positions are set by ticker or FIGI.
+	// Ticker must match single instrument, FIGI must be used for tickers traded on several exchanges.
+	Account struct {
+		// Name is key of account in State, index of account by default.
+		Name       string                   `yaml:"name"`
+		Type       sdk.AccountType          `yaml:"type"`
+		Currencies map[sdk.Currency]float64 `yaml:"currencies"`
+		Positions  []Position               `yaml:"positions"`
+	}
+
+	Position struct {
+		Ticker  string  `yaml:"ticker"`
+		FIGI    string  `yaml:"figi"`
+		Balance float64 `yaml:"balance"`
+	}
+
+	// State is snapshot of accounts after Apply.
+	State struct {
+		Accounts map[string]AccountState
+	}
+
+	AccountState struct {
+		Account   sdk.Account
+		Portfolio sdk.Portfolio
+		// Registered is true if account is registered by Apply, it is removed by Teardown.
+		// Accounts reused by Options.ReuseAccounts are cleared only.
+		Registered bool
+	}
+
+	// Options of Apply.
+	Options struct {
+		// ReuseAccounts makes Apply use existing accounts of the same type instead of registering new ones.
+		// Reused accounts are cleared, but not removed by Teardown.
+		ReuseAccounts bool
+	}
+
+	// Cleanup registers teardown of fixture, testing.TB implements it.
+	Cleanup interface {
+		Cleanup(func())
+		Errorf(format string, args ...interface{})
+	}
+
+	// Client is subset of SandboxRestClient methods used by fixtures.
+	Client interface {
+		Accounts(ctx context.Context) ([]sdk.Account, error)
+		Register(ctx context.Context, accountType sdk.AccountType) (sdk.Account, error)
+		Clear(ctx context.Context, accountID string) error
+		Remove(ctx context.Context, accountID string) error
+		SetCurrencyBalance(ctx context.Context, accountID string, currency sdk.Currency, balance float64) error
+		SetPositionsBalance(ctx context.Context, accountID, figi string, balance float64) error
+		Portfolio(ctx context.Context, accountID string) (sdk.Portfolio, error)
+		InstrumentByTicker(ctx context.Context, ticker string) ([]sdk.Instrument, error)
+	}
+)
+
+// Load decodes fixture from YAML.
+func Load(r io.Reader) (Fixture, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return Fixture{}, err
+	}
+
+	var fixture Fixture
+	if err := yaml.UnmarshalStrict(data, &fixture); err != nil {
+		return Fixture{}, fmt.Errorf("decode yaml: %w", err)
+	}
+
+	return fixture, fixture.validate()
+}
+
+// LoadFile decodes fixture from YAML file by path.
+func LoadFile(path string) (Fixture, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Fixture{}, fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+// AccountID returns id of account by fixture name.
+func (s State) AccountID(name string) (string, bool) {
+	state, ok := s.Accounts[name]
+
+	return state.Account.ID, ok
+}
+
+// Apply sets sandbox accounts to the state declared by fixture and returns snapshot of them.
+// New account is registered for every fixture account, so tests don't share accounts,
+// existing accounts are reused with opts.ReuseAccounts only. Every account is cleared before balances are set.
+// If cleanup is not nil, Teardown is called at cleanup and its error is reported by cleanup.Errorf.
+func Apply(ctx context.Context, client Client, fixture Fixture, cleanup Cleanup, opts Options) (State, error) {
+	if err := fixture.validate(); err != nil {
+		return State{}, err
+	}
+
+	var existing []sdk.Account
+	if opts.ReuseAccounts {
+		var err error
+		if existing, err = client.Accounts(ctx); err != nil {
+			return State{}, err
+		}
+	}
+
+	state := State{Accounts: make(map[string]AccountState, len(fixture.Accounts))}
+	if cleanup != nil {
+		cleanup.Cleanup(func() {
+			if err := Teardown(context.Background(), client, state); err != nil {
+				cleanup.Errorf("teardown sandbox fixture: %v", err)
+			}
+		})
+	}
+
+	used := make(map[string]bool, len(existing))
+	for i, af := range fixture.Accounts {
+		account, registered, err := fixtureAccount(ctx, client, af.accountType(), existing, used)
+		if err != nil {
+			return state, err
+		}
+		used[account.ID] = true
+		state.Accounts[af.name(i)] = AccountState{Account: account, Registered: registered}
+
+		if err := applyAccount(ctx, client, account.ID, af); err != nil {
+			return state, fmt.Errorf("account %s: %w", af.name(i), err)
+		}
+	}
+
+	return Snapshot(ctx, client, state)
+}
+
+// Snapshot returns state with actual portfolios of its accounts.
+func Snapshot(ctx context.Context, client Client, state State) (State, error) {
+	snapshot := State{Accounts: make(map[string]AccountState, len(state.Accounts))}
+	for name, account := range state.Accounts {
+		portfolio, err := client.Portfolio(ctx, account.Account.ID)
+		if err != nil {
+			return state, fmt.Errorf("account %s: %w", name, err)
+		}
+		account.Portfolio = portfolio
+		snapshot.Accounts[name] = account
+	}
+
+	return snapshot, nil
+}
+
+// Teardown removes accounts registered by Apply and clears reused ones.
+// All accounts are processed, the first error is returned.
+func Teardown(ctx context.Context, client Client, state State) error {
+	var first error
+	for name, account := range state.Accounts {
+		var err error
+		if account.Registered {
+			err = client.Remove(ctx, account.Account.ID)
+		} else {
+			err = client.Clear(ctx, account.Account.ID)
+		}
+		if err != nil && first == nil {
+			first = fmt.Errorf("account %s: %w", name, err)
+		}
+	}
+
+	return first
+}
+
+// fixtureAccount returns unused existing account of type or registers new one, existing is empty unless accounts are reused.
+func fixtureAccount(ctx context.Context, client Client, accountType sdk.AccountType, existing []sdk.Account, used map[string]bool) (sdk.Account, bool, error) {
+	for _, account := range existing {
+		if account.Type == accountType && !used[account.ID] {
+			return account, false, nil
+		}
+	}
+
+	account, err := client.Register(ctx, accountType)
+	if err != nil {
+		return sdk.Account{}, false, err
+	}
+
+	return account, true, nil
+}
+
+func applyAccount(ctx context.Context, client Client, accountID string, af Account) error {
+	if err := client.Clear(ctx, accountID); err != nil {
+		return err
+	}
+
+	for currency, balance := range af.Currencies {
+		if err := client.SetCurrencyBalance(ctx, accountID, currency, balance); err != nil {
+			return fmt.Errorf("currency %s: %w", currency, err)
+		}
+	}
+
+	for _, p := range af.Positions {
+		figi, err := positionFIGI(ctx, client, p)
+		if err != nil {
+			return err
+		}
+		if err := client.SetPositionsBalance(ctx, accountID, figi, p.Balance); err != nil {
+			return fmt.Errorf("position %s: %w", figi, err)
+		}
+	}
+
+	return nil
+}
+
+func positionFIGI(ctx context.Context, client Client, p Position) (string, error) {
+	if p.FIGI != "" {
+		return p.FIGI, nil
+	}
+
+	instruments, err := client.InstrumentByTicker(ctx, p.Ticker)
+	if err != nil {
+		return "", fmt.Errorf("ticker %s: %w", p.Ticker, err)
+	}
+	switch len(instruments) {
+	case 0:
+		return "", fmt.Errorf("ticker %s: %w", p.Ticker, sdk.ErrNotFound)
+	case 1:
+		return instruments[0].FIGI, nil
+	default:
+		figis := make([]string, len(instruments))
+		for i, instrument := range instruments {
+			figis[i] = instrument.FIGI
+		}
+		return "", fmt.Errorf("ticker %s: %w: %s", p.Ticker, ErrAmbiguousTicker, strings.Join(figis, ", "))
+	}
+}
+
+func (f Fixture) validate() error {
+	names := make(map[string]bool, len(f.Accounts))
+	for i, af := range f.Accounts {
+		name := af.name(i)
+		if names[name] {
+			return fmt.Errorf("%w: duplicate account %q", ErrInvalidFixture, name)
+		}
+		names[name] = true
+
+		if t := af.accountType(); t != sdk.AccountTinkoff && t != sdk.AccountTinkoffIIS {
+			return fmt.Errorf("%w: account %q has unknown type %q", ErrInvalidFixture, name, t)
+		}
+		for _, p := range af.Positions {
+			if (p.Ticker == "") == (p.FIGI == "") {
+				return fmt.Errorf("%w: account %q position should have either ticker or figi", ErrInvalidFixture, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (af Account) name(i int) string {
+	if af.Name == "" {
+		return strconv.Itoa(i)
+	}
+
+	return af.Name
+}
+
+func (af Account) accountType() sdk.AccountType {
+	if af.Type == "" {
+		return sdk.AccountTinkoff
+	}
+
+	return af.Type
+}
@@ -0,0 +1,158 @@
+package sandboxfixture
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	sdk "github.com/Tinkoff/invest-openapi-go-sdk"
+)
+
+// fakeClient is in-memory sandbox with positions by FIGI.
+type fakeClient struct {
+	accounts  []sdk.Account
+	removed   []string
+	positions map[string]map[string]float64 // account id -> figi -> balance
+	tickers   map[string][]sdk.Instrument
+}
+
+func newFakeClient(existing ...sdk.Account) *fakeClient {
+	return &fakeClient{
+		accounts:  existing,
+		positions: make(map[string]map[string]float64),
+		tickers: map[string][]sdk.Instrument{
+			"SBER": {{FIGI: "BBG004730N88", Ticker: "SBER"}},
+			"AAPL": {{FIGI: "BBG000B9XRY4", Ticker: "AAPL"}, {FIGI: "BBG000B9Y5X2", Ticker: "AAPL"}},
+		},
+	}
+}
+
+func (c *fakeClient) Accounts(context.Context) ([]sdk.Account, error) {
+	return c.accounts, nil
+}
+
+func (c *fakeClient) Register(_ context.Context, accountType sdk.AccountType) (sdk.Account, error) {
+	account := sdk.Account{Type: accountType, ID: "new-" + strconv.Itoa(len(c.accounts))}
+	c.accounts = append(c.accounts, account)
+
+	return account, nil
+}
+
+func (c *fakeClient) Clear(_ context.Context, accountID string) error {
+	c.positions[accountID] = make(map[string]float64)
+
+	return nil
+}
+
+func (c *fakeClient) Remove(_ context.Context, accountID string) error {
+	c.removed = append(c.removed, accountID)
+
+	return nil
+}
+
+func (c *fakeClient) SetCurrencyBalance(context.Context, string, sdk.Currency, float64) error {
+	return nil
+}
+
+func (c *fakeClient) SetPositionsBalance(_ context.Context, accountID, figi string, balance float64) error {
+	c.positions[accountID][figi] = balance
+
+	return nil
+}
+
+func (c *fakeClient) Portfolio(_ context.Context, accountID string) (sdk.Portfolio, error) {
+	var portfolio sdk.Portfolio
+	for figi, balance := range c.positions[accountID] {
+		portfolio.Positions = append(portfolio.Positions, sdk.PositionBalance{FIGI: figi, Balance: balance})
+	}
+
+	return portfolio, nil
+}
+
+func (c *fakeClient) InstrumentByTicker(_ context.Context, ticker string) ([]sdk.Instrument, error) {
+	return c.tickers[ticker], nil
+}
+
+const testFixture = `
+accounts:
+  - name: main
+    positions:
+      - ticker: SBER
+        balance: 10
+`
+
+func TestApplyRegistersAccounts(t *testing.T) {
+	fixture, err := Load(strings.NewReader(testFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	existing := sdk.Account{Type: sdk.AccountTinkoff, ID: "existing"}
+	client := newFakeClient(existing)
+
+	state, err := Apply(context.Background(), client, fixture, nil, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	main := state.Accounts["main"]
+	if main.Account.ID == existing.ID || !main.Registered {
+		t.Fatalf("existing account is reused by default: %+v", main)
+	}
+	if len(main.Portfolio.Positions) != 1 || main.Portfolio.Positions[0].FIGI != "BBG004730N88" {
+		t.Fatalf("got portfolio %+v", main.Portfolio)
+	}
+
+	if err := Teardown(context.Background(), client, state); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.removed) != 1 || client.removed[0] != main.Account.ID {
+		t.Fatalf("removed %v", client.removed)
+	}
+}
+
+func TestApplyReuseAccounts(t *testing.T) {
+	fixture, err := Load(strings.NewReader(testFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := newFakeClient(sdk.Account{Type: sdk.AccountTinkoff, ID: "existing"})
+	state, err := Apply(context.Background(), client, fixture, nil, Options{ReuseAccounts: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if main := state.Accounts["main"]; main.Account.ID != "existing" || main.Registered {
+		t.Fatalf("got %+v", main)
+	}
+
+	if err := Teardown(context.Background(), client, state); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.removed) != 0 {
+		t.Fatalf("reused account is removed: %v", client.removed)
+	}
+}
+
+func TestApplyAmbiguousTicker(t *testing.T) {
+	fixture := Fixture{Accounts: []Account{{Positions: []Position{{Ticker: "AAPL", Balance: 1}}}}}
+
+	_, err := Apply(context.Background(), newFakeClient(), fixture, nil, Options{})
+	if !errors.Is(err, ErrAmbiguousTicker) {
+		t.Fatalf("want ErrAmbiguousTicker, got %v", err)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for _, data := range []string{
+		"accounts:\n  - positions:\n      - balance: 1\n",
+		"accounts:\n  - type: Broker\n",
+		"accounts:\n  - name: a\n  - name: a\n",
+		"accounts:\n  - unknown: 1\n",
+	} {
+		if _, err := Load(strings.NewReader(data)); err == nil {
+			t.Fatalf("no error for %q", data)
+		}
+	}
+}
@@ -1,10 +1,12 @@
 package sdk
 
 import (
+	"context"
 	"encoding/json"
 	"net"
 	"net/http"
-	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -16,187 +18,488 @@ const StreamingApiURL = "wss://api-invest.tinkoff.ru/openapi/md/v1/md-openapi/ws
 const DefaultPongWait = 60 * time.Second
 const DefaultPingPeriod = 54 * time.Second
 
+// writeWait is time allowed to write a message to the connection.
+const writeWait = 10 * time.Second
+
 type Logger interface {
 	Printf(format string, args ...interface{})
 }
 
+// PingPongConfig is keepalive config of streaming connection, see NewPingPongConfig.
 type PingPongConfig struct {
 	isEnabled  bool
 	pongWait   time.Duration
 	pingPeriod time.Duration
 }
 
+// StreamingClient is safe for concurrent use: all writes to the connection are done by single writer goroutine,
+// Subscribe* and Unsubscribe* methods may be called from any goroutine.
 type StreamingClient struct {
-	logger Logger
+	// settingsMu guards logger, metrics and tracer which may be replaced while connection is used.
+	settingsMu sync.RWMutex
+	logger     StructuredLogger
+	metrics    StreamingMetrics
+	tracer     Tracer
+
 	conn   *websocket.Conn
 	token  string
 	apiURL string
+	dialer websocket.Dialer
 
 	pingPongCfg *PingPongConfig
-	pingTicker  *time.Ticker
+	recorder    MessageRecorder
+
+	writes    chan writeRequest
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+	wg        sync.WaitGroup
+
+	readStopped int32
+
+	requestIDPrefix string
+	requestSeq      uint64
+
+	subsMu   sync.Mutex
+	subs     map[subscriptionKey]*subscriptionState
+	requests map[string]subscriptionKey // request id -> subscription
+}
+
+type writeRequest struct {
+	data   []byte
+	result chan error
 }
 
-func NewStreamingClient(logger Logger, token string) (*StreamingClient, error) {
-	return NewStreamingClientCustom(logger, token, StreamingApiURL)
+// NewStreamingClient connects to streaming api, see StreamingOption for configuration.
+func NewStreamingClient(logger Logger, token string, options ...StreamingOption) (*StreamingClient, error) {
+	return NewStreamingClientContext(context.Background(), logger, token, options...)
 }
 
+// NewStreamingClientCustom for backward compatibility only.
+// Deprecated: have to use NewStreamingClient with WithStreamingURL option.
 func NewStreamingClientCustom(logger Logger, token, apiURL string) (*StreamingClient, error) {
-	return NewStreamingClientCustomPingPong(logger, token, apiURL, &PingPongConfig{false, DefaultPongWait, DefaultPingPeriod})
+	return NewStreamingClient(logger, token, WithStreamingURL(apiURL))
 }
 
+// NewStreamingClientCustomPingPong for backward compatibility only.
+// Deprecated: have to use NewStreamingClient with WithStreamingURL and WithStreamingKeepalive options.
 func NewStreamingClientCustomPingPong(logger Logger, token, apiURL string, pingPongCfg *PingPongConfig) (*StreamingClient, error) {
+	return NewStreamingClient(logger, token, WithStreamingURL(apiURL), func(client *StreamingClient) {
+		client.pingPongCfg = pingPongCfg
+	})
+}
+
+// NewStreamingClientContext connects to streaming api, ctx bounds connection establishment only.
+func NewStreamingClientContext(ctx context.Context, logger Logger, token string, options ...StreamingOption) (*StreamingClient, error) {
 	client := &StreamingClient{
-		logger: logger,
+		logger: NewPrintfLogger(logger, LogInfo),
 		token:  token,
-		apiURL: apiURL,
+		apiURL: StreamingApiURL,
+		dialer: websocket.Dialer{
+			Proxy:            http.ProxyFromEnvironment,
+			HandshakeTimeout: DefaultHandshakeTimeout,
+		},
+
+		pingPongCfg: &PingPongConfig{false, DefaultPongWait, DefaultPingPeriod},
+		metrics:     nopStreamingMetrics{},
+		tracer:      nopTracer{},
+
+		writes: make(chan writeRequest),
+		done:   make(chan struct{}),
+
+		requestIDPrefix: randomRequestIDPrefix(),
+		subs:            make(map[subscriptionKey]*subscriptionState),
+		requests:        make(map[string]subscriptionKey),
+	}
 
-		pingPongCfg: pingPongCfg,
+	for i := range options {
+		options[i](client)
 	}
+	client.logger = redactLogger(client.logger, token)
 
-	conn, err := client.connect()
+	conn, err := client.connect(ctx)
 	if err != nil {
 		return nil, err
 	}
 	client.conn = conn
+	client.logger.Log(LogInfo, "Streaming connection is opened", Field{Key: "url", Value: client.apiURL})
+
+	client.wg.Add(1)
+	go client.writeLoop()
 
 	return client, nil
 }
 
+// Close stops writer goroutine, sends close message and closes the connection. It is safe to call Close several times.
 func (c *StreamingClient) Close() error {
-	c.pingTicker.Stop()
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.wg.Wait()
+		c.closeErr = c.conn.Close()
+	})
+
+	return c.closeErr
+}
+
+// SetLogger replaces Printf logger passed to constructor, token is redacted from all records.
+// It is safe to call it while connection is used, prefer WithStreamingLogger option.
+func (c *StreamingClient) SetLogger(logger StructuredLogger) {
+	logger = redactLogger(logger, c.token)
+
+	c.settingsMu.Lock()
+	defer c.settingsMu.Unlock()
 
-	return c.conn.Close()
+	c.logger = logger
+}
+
+func (c *StreamingClient) currentLogger() StructuredLogger {
+	c.settingsMu.RLock()
+	defer c.settingsMu.RUnlock()
+
+	return c.logger
+}
+
+// SetRecorder sets recorder for all raw messages received by read loop, it must be called before RunReadLoop.
+func (c *StreamingClient) SetRecorder(recorder MessageRecorder) {
+	c.recorder = recorder
 }
 
 func (c *StreamingClient) RunReadLoop(fn func(event interface{}) error) error {
+	return c.RunReadLoopContext(context.Background(), fn)
+}
+
+// RunReadLoopContext reads events until error or ctx cancellation, ctx.Err() is returned in the last case.
+// Connection can't be read anymore after cancellation, so the client should be closed.
+func (c *StreamingClient) RunReadLoopContext(ctx context.Context, fn func(event interface{}) error) (err error) {
+	defer func() {
+		c.failPending(err)
+	}()
+
+	if ctx.Done() != nil {
+		stop := make(chan struct{})
+		defer close(stop)
+
+		go func() {
+			select {
+			case <-ctx.Done():
+				// Unblock ReadMessage, pong handler must not extend deadline after this point.
+				atomic.StoreInt32(&c.readStopped, 1)
+				c.conn.SetReadDeadline(time.Now())
+			case <-stop:
+			}
+		}()
+	}
+
 	for {
 		_, msg, err := c.conn.ReadMessage()
 		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
 			return errors.Wrap(err, "can't read message")
 		}
 
-		var event Event
-		if err := json.Unmarshal(msg, &event); err != nil {
-			c.logger.Printf("Can't unmarshal event %s", msg)
+		received := time.Now()
+
+		if c.recorder != nil {
+			if err := c.recorder.Record(received, msg); err != nil {
+				c.currentLogger().Log(LogWarn, "Can't record message", Field{Key: "error", Value: err})
+			}
+		}
+
+		event, name, err := decodeEvent(msg)
+		if err != nil {
+			logDecodeError(c.currentLogger(), name, msg, err)
+			c.currentMetrics().DecodeFailed(name)
 			continue
 		}
 
-		switch event.Name {
-		case "candle":
-			var event CandleEvent
-			if err := json.Unmarshal(msg, &event); err != nil {
-				c.logger.Printf("Can't unmarshal event candle %s", msg)
-				continue
-			}
-			if err := fn(event); err != nil {
-				return err
-			}
-		case "orderbook":
-			var event OrderBookEvent
-			if err := json.Unmarshal(msg, &event); err != nil {
-				c.logger.Printf("Can't unmarshal event orderbook %s", msg)
-				continue
-			}
-			if err := fn(event); err != nil {
-				return err
-			}
-		case "instrument_info":
-			var event InstrumentInfoEvent
-			if err := json.Unmarshal(msg, &event); err != nil {
-				c.logger.Printf("Can't unmarshal event instrument_info %s", msg)
-				continue
-			}
-			if err := fn(event); err != nil {
-				return err
-			}
-		case "error":
-			var event ErrorEvent
-			if err := json.Unmarshal(msg, &event); err != nil {
-				c.logger.Printf("Can't unmarshal event error %s", msg)
-				continue
-			}
-			if err := fn(event); err != nil {
-				return err
-			}
-		default:
-			c.logger.Printf("Get unknown event %s", msg)
+		c.trackSubscription(event)
+		c.observeEvent(event, name, received)
+
+		if err := c.traceReceived(ctx, event, name, fn); err != nil {
+			return err
 		}
 	}
 }
 
+var errUnknownEvent = errors.New("unknown event")
+
+// decodeEvent returns event name and one of CandleEvent, OrderBookEvent, InstrumentInfoEvent, ErrorEvent.
+func decodeEvent(msg []byte) (interface{}, string, error) {
+	var event Event
+	if err := json.Unmarshal(msg, &event); err != nil {
+		return nil, "", err
+	}
+
+	switch event.Name {
+	case "candle":
+		var event CandleEvent
+		err := json.Unmarshal(msg, &event)
+		return event, "candle", err
+	case "orderbook":
+		var event OrderBookEvent
+		err := json.Unmarshal(msg, &event)
+		return event, "orderbook", err
+	case "instrument_info":
+		var event InstrumentInfoEvent
+		err := json.Unmarshal(msg, &event)
+		return event, "instrument_info", err
+	case "error":
+		var event ErrorEvent
+		err := json.Unmarshal(msg, &event)
+		return event, "error", err
+	default:
+		return nil, event.Name, errUnknownEvent
+	}
+}
+
+func logDecodeError(logger StructuredLogger, name string, msg []byte, err error) {
+	if err == errUnknownEvent {
+		logger.Log(LogWarn, "Get unknown event", Field{Key: "event", Value: name}, Field{Key: "payload", Value: truncatePayload(msg)})
+		return
+	}
+
+	logger.Log(LogWarn, "Can't unmarshal event",
+		Field{Key: "event", Value: name},
+		Field{Key: "error", Value: err},
+		Field{Key: "payload", Value: truncatePayload(msg)},
+	)
+}
+
+// SubscribeCandle sends subscription request without waiting for acknowledgement.
+// Empty requestID is replaced by generated one, failed subscription is reported by ErrorEvent and SubscriptionByRequestID.
 func (c *StreamingClient) SubscribeCandle(figi string, interval CandleInterval, requestID string) error {
-	sub := `{ "event": "candle:subscribe", "request_id": "` + requestID + `", "figi": "` + figi + `", "interval": "` + string(interval) + `"}`
+	return c.subscribe(context.Background(), candleSubscription(figi, interval, requestID), false)
+}
 
-	if err := c.conn.WriteMessage(websocket.TextMessage, []byte(sub)); err != nil {
-		return errors.Wrap(err, "can't subscribe to event")
+// SubscribeCandleContext subscribes to candles and waits for the first candle or error event for the request.
+// Read loop must be running to receive acknowledgement. Empty requestID is replaced by generated one.
+func (c *StreamingClient) SubscribeCandleContext(ctx context.Context, figi string, interval CandleInterval, requestID string) error {
+	return c.subscribe(ctx, candleSubscription(figi, interval, requestID), true)
+}
+
+// SubscribeCandleBatch sends subscription requests for every figi without waiting for acknowledgement.
+// All figis are validated before the first request is sent.
+func (c *StreamingClient) SubscribeCandleBatch(ctx context.Context, figis []string, interval CandleInterval) error {
+	subs := make([]Subscription, 0, len(figis))
+	for _, figi := range figis {
+		subs = append(subs, candleSubscription(figi, interval, ""))
 	}
 
-	return nil
+	return c.subscribeBatch(ctx, subs)
 }
 
 func (c *StreamingClient) UnsubscribeCandle(figi string, interval CandleInterval, requestID string) error {
-	sub := `{ "event": "candle:unsubscribe", "request_id": "` + requestID + `", "figi": "` + figi + `", "interval": "` + string(interval) + `"}`
-	if err := c.conn.WriteMessage(websocket.TextMessage, []byte(sub)); err != nil {
-		return errors.Wrap(err, "can't unsubscribe from event")
+	return c.UnsubscribeCandleContext(context.Background(), figi, interval, requestID)
+}
+
+// UnsubscribeCandleContext sends unsubscribe request, server doesn't acknowledge it.
+func (c *StreamingClient) UnsubscribeCandleContext(ctx context.Context, figi string, interval CandleInterval, requestID string) error {
+	return c.unsubscribe(ctx, candleSubscription(figi, interval, requestID))
+}
+
+// UnsubscribeCandleBatch sends unsubscribe requests for every figi.
+func (c *StreamingClient) UnsubscribeCandleBatch(ctx context.Context, figis []string, interval CandleInterval) error {
+	subs := make([]Subscription, 0, len(figis))
+	for _, figi := range figis {
+		subs = append(subs, candleSubscription(figi, interval, ""))
 	}
 
-	return nil
+	return c.unsubscribeBatch(ctx, subs)
 }
 
+// SubscribeOrderbook sends subscription request without waiting for acknowledgement.
+// Empty requestID is replaced by generated one, failed subscription is reported by ErrorEvent and SubscriptionByRequestID.
 func (c *StreamingClient) SubscribeOrderbook(figi string, depth int, requestID string) error {
-	if depth < 1 || depth > MaxOrderbookDepth {
-		return ErrDepth
-	}
+	return c.subscribe(context.Background(), orderbookSubscription(figi, depth, requestID), false)
+}
 
-	sub := `{ "event": "orderbook:subscribe", "request_id": "` + requestID + `", "figi": "` + figi + `", "depth": ` + strconv.Itoa(depth) + `}`
-	if err := c.conn.WriteMessage(websocket.TextMessage, []byte(sub)); err != nil {
-		return errors.Wrap(err, "can't subscribe to event")
+// SubscribeOrderbookContext subscribes to orderbook and waits for the first orderbook or error event for the request.
+// Read loop must be running to receive acknowledgement. Empty requestID is replaced by generated one.
+func (c *StreamingClient) SubscribeOrderbookContext(ctx context.Context, figi string, depth int, requestID string) error {
+	return c.subscribe(ctx, orderbookSubscription(figi, depth, requestID), true)
+}
+
+// SubscribeOrderbookBatch sends subscription requests for every figi without waiting for acknowledgement.
+// All figis are validated before the first request is sent.
+func (c *StreamingClient) SubscribeOrderbookBatch(ctx context.Context, figis []string, depth int) error {
+	subs := make([]Subscription, 0, len(figis))
+	for _, figi := range figis {
+		subs = append(subs, orderbookSubscription(figi, depth, ""))
 	}
 
-	return nil
+	return c.subscribeBatch(ctx, subs)
 }
 
 func (c *StreamingClient) UnsubscribeOrderbook(figi string, depth int, requestID string) error {
-	if depth < 1 || depth > MaxOrderbookDepth {
-		return ErrDepth
-	}
+	return c.UnsubscribeOrderbookContext(context.Background(), figi, depth, requestID)
+}
 
-	sub := `{ "event": "orderbook:unsubscribe", "request_id": "` + requestID + `", "figi": "` + figi + `", "depth": ` + strconv.Itoa(depth) + `}`
-	if err := c.conn.WriteMessage(websocket.TextMessage, []byte(sub)); err != nil {
-		return errors.Wrap(err, "can't unsubscribe from event")
+// UnsubscribeOrderbookContext sends unsubscribe request, server doesn't acknowledge it.
+func (c *StreamingClient) UnsubscribeOrderbookContext(ctx context.Context, figi string, depth int, requestID string) error {
+	return c.unsubscribe(ctx, orderbookSubscription(figi, depth, requestID))
+}
+
+// UnsubscribeOrderbookBatch sends unsubscribe requests for every figi.
+func (c *StreamingClient) UnsubscribeOrderbookBatch(ctx context.Context, figis []string, depth int) error {
+	subs := make([]Subscription, 0, len(figis))
+	for _, figi := range figis {
+		subs = append(subs, orderbookSubscription(figi, depth, ""))
 	}
 
-	return nil
+	return c.unsubscribeBatch(ctx, subs)
 }
 
+// SubscribeInstrumentInfo sends subscription request without waiting for acknowledgement.
+// Empty requestID is replaced by generated one, failed subscription is reported by ErrorEvent and SubscriptionByRequestID.
 func (c *StreamingClient) SubscribeInstrumentInfo(figi, requestID string) error {
-	sub := `{"event": "instrument_info:subscribe", "request_id": "` + requestID + `", "figi": "` + figi + `"}`
-	if err := c.conn.WriteMessage(websocket.TextMessage, []byte(sub)); err != nil {
-		return errors.Wrap(err, "can't subscribe to event")
+	return c.subscribe(context.Background(), instrumentInfoSubscription(figi, requestID), false)
+}
+
+// SubscribeInstrumentInfoContext subscribes to instrument info and waits for the first instrument info or error event for the request.
+// Read loop must be running to receive acknowledgement. Empty requestID is replaced by generated one.
+func (c *StreamingClient) SubscribeInstrumentInfoContext(ctx context.Context, figi, requestID string) error {
+	return c.subscribe(ctx, instrumentInfoSubscription(figi, requestID), true)
+}
+
+// SubscribeInstrumentInfoBatch sends subscription requests for every figi without waiting for acknowledgement.
+// All figis are validated before the first request is sent.
+func (c *StreamingClient) SubscribeInstrumentInfoBatch(ctx context.Context, figis []string) error {
+	subs := make([]Subscription, 0, len(figis))
+	for _, figi := range figis {
+		subs = append(subs, instrumentInfoSubscription(figi, ""))
 	}
 
-	return nil
+	return c.subscribeBatch(ctx, subs)
 }
 
 func (c *StreamingClient) UnsubscribeInstrumentInfo(figi, requestID string) error {
-	sub := `{"event": "instrument_info:unsubscribe", "request_id": "` + requestID + `", "figi": "` + figi + `"}`
-	if err := c.conn.WriteMessage(websocket.TextMessage, []byte(sub)); err != nil {
-		return errors.Wrap(err, "can't unsubscribe from event")
+	return c.UnsubscribeInstrumentInfoContext(context.Background(), figi, requestID)
+}
+
+// UnsubscribeInstrumentInfoContext sends unsubscribe request, server doesn't acknowledge it.
+func (c *StreamingClient) UnsubscribeInstrumentInfoContext(ctx context.Context, figi, requestID string) error {
+	return c.unsubscribe(ctx, instrumentInfoSubscription(figi, requestID))
+}
+
+// UnsubscribeInstrumentInfoBatch sends unsubscribe requests for every figi.
+func (c *StreamingClient) UnsubscribeInstrumentInfoBatch(ctx context.Context, figis []string) error {
+	subs := make([]Subscription, 0, len(figis))
+	for _, figi := range figis {
+		subs = append(subs, instrumentInfoSubscription(figi, ""))
+	}
+
+	return c.unsubscribeBatch(ctx, subs)
+}
+
+func candleSubscription(figi string, interval CandleInterval, requestID string) Subscription {
+	return Subscription{RequestID: requestID, Event: "candle", FIGI: figi, Interval: interval}
+}
+
+func orderbookSubscription(figi string, depth int, requestID string) Subscription {
+	return Subscription{RequestID: requestID, Event: "orderbook", FIGI: figi, Depth: depth}
+}
+
+func instrumentInfoSubscription(figi, requestID string) Subscription {
+	return Subscription{RequestID: requestID, Event: "instrument_info", FIGI: figi}
+}
+
+// subscriptionRequest is subscribe or unsubscribe request of streaming API.
+type subscriptionRequest struct {
+	Event     string         `json:"event"`
+	RequestID string         `json:"request_id"`
+	FIGI      string         `json:"figi"`
+	Interval  CandleInterval `json:"interval,omitempty"`
+	Depth     int            `json:"depth,omitempty"`
+}
+
+// subscriptionMessage returns subscribe or unsubscribe request for subscription, action is subscribe or unsubscribe.
+func subscriptionMessage(action string, sub Subscription) ([]byte, error) {
+	msg, err := json.Marshal(subscriptionRequest{
+		Event:     sub.Event + ":" + action,
+		RequestID: sub.RequestID,
+		FIGI:      sub.FIGI,
+		Interval:  sub.Interval,
+		Depth:     sub.Depth,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't marshal subscription request")
 	}
 
-	return nil
+	return msg, nil
 }
 
 var ErrForbidden = errors.New("invalid token")
 var ErrUnauthorized = errors.New("token not provided")
+var ErrStreamingClosed = errors.New("streaming client closed")
 
-func (c *StreamingClient) connect() (*websocket.Conn, error) {
-	dialer := websocket.Dialer{
-		Proxy:            http.ProxyFromEnvironment,
-		HandshakeTimeout: 5 * time.Second,
+// StreamingError is set to Subscription.Err and returned by Subscribe*Context methods when server responds by error event for the request.
+type StreamingError struct {
+	RequestID string
+	Message   string
+}
+
+func (e *StreamingError) Error() string {
+	return "streaming error for request " + e.RequestID + ": " + e.Message
+}
+
+// send queues message to writer goroutine and waits for the write result.
+func (c *StreamingClient) send(ctx context.Context, data []byte) error {
+	req := writeRequest{data: data, result: make(chan error, 1)}
+
+	select {
+	case c.writes <- req:
+	case <-c.done:
+		return ErrStreamingClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	// Writer goroutine always replies to accepted request.
+	return <-req.result
+}
+
+// writeLoop is the only goroutine which writes data messages to the connection.
+// It also sends keepalive pings every ping period if ping-pong is enabled.
+func (c *StreamingClient) writeLoop() {
+	defer c.wg.Done()
+
+	var ping <-chan time.Time
+	if c.pingPongCfg.isEnabled {
+		ticker := time.NewTicker(c.pingPongCfg.pingPeriod)
+		defer ticker.Stop()
+		ping = ticker.C
+	}
+
+	for {
+		select {
+		case req := <-c.writes:
+			req.result <- c.write(websocket.TextMessage, req.data)
+		case <-ping:
+			if err := c.write(websocket.PingMessage, nil); err != nil {
+				c.currentLogger().Log(LogWarn, "Can't send ping", Field{Key: "error", Value: err})
+			}
+		case <-c.done:
+			msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+			if err := c.write(websocket.CloseMessage, msg); err != nil && err != websocket.ErrCloseSent {
+				c.currentLogger().Log(LogWarn, "Can't send close message", Field{Key: "error", Value: err})
+			}
+			return
+		}
+	}
+}
+
+func (c *StreamingClient) write(messageType int, data []byte) error {
+	if err := c.conn.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+		return errors.Wrap(err, "can't set write deadline")
 	}
 
-	conn, resp, err := dialer.Dial(c.apiURL, http.Header{"Authorization": {"Bearer " + c.token}})
+	return c.conn.WriteMessage(messageType, data)
+}
+
+func (c *StreamingClient) connect(ctx context.Context) (*websocket.Conn, error) {
+	conn, resp, err := c.dialer.DialContext(ctx, c.apiURL, http.Header{"Authorization": {"Bearer " + c.token}})
 	if err != nil {
 		if resp != nil {
 			if resp.StatusCode == http.StatusForbidden {
@@ -226,19 +529,12 @@ func (c *StreamingClient) connect() (*websocket.Conn, error) {
 		})
 
 		conn.SetPongHandler(func(string) error {
+			if atomic.LoadInt32(&c.readStopped) == 1 {
+				return nil
+			}
 			conn.SetReadDeadline(time.Now().Add(c.pingPongCfg.pongWait))
 			return nil
 		})
-
-		c.pingTicker = time.NewTicker(c.pingPongCfg.pingPeriod)
-
-		go func() {
-			<-c.pingTicker.C
-
-			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-				return
-			}
-		}()
 	}
 
 	return conn, nil
@@ -0,0 +1,261 @@
+package sdk
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultHubBufferSize is default size of event buffer of every hub consumer.
+const DefaultHubBufferSize = 128
+
+type (
+	// StreamingHub delivers events of single StreamingClient to many independent consumers.
+	// Subscriptions are reference counted by (event, figi, interval/depth): subscribe request is sent
+	// for the first consumer and unsubscribe request only when the last consumer leaves.
+	StreamingHub struct {
+		client     *StreamingClient
+		bufferSize int
+
+		// opMu orders subscribe and unsubscribe requests of the same key.
+		opMu   sync.Mutex
+		mu     sync.Mutex
+		topics map[subscriptionKey]*hubTopic
+	}
+
+	hubTopic struct {
+		sub       Subscription
+		state     *subscriptionState
+		consumers map[*HubSubscription]struct{}
+	}
+
+	// HubSubscription is consumer of StreamingHub events. Slow consumer doesn't block others:
+	// events which don't fit into the buffer are dropped and counted.
+	HubSubscription struct {
+		hub     *StreamingHub
+		key     subscriptionKey
+		events  chan interface{}
+		dropped uint64
+
+		closeOnce sync.Once
+		closed    bool // guarded by hub.mu
+	}
+)
+
+// NewStreamingHub returns hub on top of client. Hub owns client read loop, use Run instead of RunReadLoop.
+// Buffer size of every consumer is bufferSize or DefaultHubBufferSize if bufferSize <= 0.
+func NewStreamingHub(client *StreamingClient, bufferSize int) *StreamingHub {
+	if bufferSize <= 0 {
+		bufferSize = DefaultHubBufferSize
+	}
+
+	return &StreamingHub{
+		client:     client,
+		bufferSize: bufferSize,
+		topics:     make(map[subscriptionKey]*hubTopic),
+	}
+}
+
+// Run reads client events and delivers them to consumers until error or ctx cancellation.
+// Events channels of all consumers are closed on return.
+func (h *StreamingHub) Run(ctx context.Context) error {
+	defer h.closeAll()
+
+	return h.client.RunReadLoopContext(ctx, func(event interface{}) error {
+		h.dispatch(event)
+		return nil
+	})
+}
+
+// SubscribeCandle returns consumer of candles, it waits for acknowledgement like StreamingClient.SubscribeCandleContext.
+func (h *StreamingHub) SubscribeCandle(ctx context.Context, figi string, interval CandleInterval) (*HubSubscription, error) {
+	return h.subscribe(ctx, candleSubscription(figi, interval, ""))
+}
+
+// SubscribeOrderbook returns consumer of orderbooks, it waits for acknowledgement like StreamingClient.SubscribeOrderbookContext.
+func (h *StreamingHub) SubscribeOrderbook(ctx context.Context, figi string, depth int) (*HubSubscription, error) {
+	if depth < 1 || depth > MaxOrderbookDepth {
+		return nil, ErrDepth
+	}
+
+	return h.subscribe(ctx, orderbookSubscription(figi, depth, ""))
+}
+
+// SubscribeInstrumentInfo returns consumer of instrument info, it waits for acknowledgement like StreamingClient.SubscribeInstrumentInfoContext.
+func (h *StreamingHub) SubscribeInstrumentInfo(ctx context.Context, figi string) (*HubSubscription, error) {
+	return h.subscribe(ctx, instrumentInfoSubscription(figi, ""))
+}
+
+// Consumers returns count of consumers by subscription.
+func (h *StreamingHub) Consumers() map[Subscription]int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	counts := make(map[Subscription]int, len(h.topics))
+	for _, topic := range h.topics {
+		counts[topic.sub] = len(topic.consumers)
+	}
+
+	return counts
+}
+
+// Events returns channel of CandleEvent, OrderBookEvent, InstrumentInfoEvent or ErrorEvent of the subscription.
+// Channel is closed when consumer is closed, subscription is failed or hub is stopped.
+func (s *HubSubscription) Events() <-chan interface{} {
+	return s.events
+}
+
+// Dropped returns count of events dropped because consumer buffer was full.
+func (s *HubSubscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Close removes consumer from the hub, unsubscribe request is sent if it is the last consumer of the subscription.
+func (s *HubSubscription) Close(ctx context.Context) error {
+	var err error
+	s.closeOnce.Do(func() {
+		err = s.hub.leave(ctx, s)
+	})
+
+	return err
+}
+
+func (h *StreamingHub) subscribe(ctx context.Context, sub Subscription) (*HubSubscription, error) {
+	key := subscriptionKeyOf(sub)
+	consumer := &HubSubscription{hub: h, key: key, events: make(chan interface{}, h.bufferSize)}
+
+	h.opMu.Lock()
+
+	h.mu.Lock()
+	topic, ok := h.topics[key]
+	if !ok {
+		topic = &hubTopic{sub: sub, consumers: make(map[*HubSubscription]struct{})}
+		h.topics[key] = topic
+	}
+	topic.consumers[consumer] = struct{}{}
+	h.mu.Unlock()
+
+	if !ok {
+		state, err := h.client.startSubscription(ctx, sub)
+		if err != nil {
+			h.drop(topic)
+			h.opMu.Unlock()
+			return nil, err
+		}
+
+		h.mu.Lock()
+		topic.state = state
+		topic.sub.RequestID = state.sub.RequestID
+		h.mu.Unlock()
+	}
+	state := topic.state
+
+	h.opMu.Unlock()
+
+	if err := h.client.waitSubscription(ctx, state); err != nil {
+		if _, failed := err.(*StreamingError); failed {
+			h.drop(topic)
+		} else {
+			_ = consumer.Close(context.Background())
+		}
+		return nil, err
+	}
+
+	return consumer, nil
+}
+
+func (h *StreamingHub) leave(ctx context.Context, consumer *HubSubscription) error {
+	h.opMu.Lock()
+	defer h.opMu.Unlock()
+
+	h.mu.Lock()
+	if !consumer.closed {
+		consumer.closed = true
+		close(consumer.events)
+	}
+
+	topic, ok := h.topics[consumer.key]
+	if !ok {
+		h.mu.Unlock()
+		return nil
+	}
+
+	delete(topic.consumers, consumer)
+	if len(topic.consumers) > 0 {
+		h.mu.Unlock()
+		return nil
+	}
+
+	delete(h.topics, consumer.key)
+	h.mu.Unlock()
+
+	return h.client.unsubscribe(ctx, topic.sub)
+}
+
+// drop removes failed topic and closes its consumers.
+func (h *StreamingHub) drop(topic *hubTopic) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.topics[subscriptionKeyOf(topic.sub)] == topic {
+		delete(h.topics, subscriptionKeyOf(topic.sub))
+	}
+
+	for consumer := range topic.consumers {
+		if !consumer.closed {
+			consumer.closed = true
+			close(consumer.events)
+		}
+	}
+}
+
+func (h *StreamingHub) dispatch(event interface{}) {
+	key, ok := eventSubscriptionKey(event)
+	if !ok {
+		e, isError := event.(ErrorEvent)
+		if !isError {
+			return
+		}
+
+		sub, found := h.client.SubscriptionByRequestID(e.Error.RequestID)
+		if !found {
+			return
+		}
+		key = subscriptionKeyOf(sub)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	topic, ok := h.topics[key]
+	if !ok {
+		return
+	}
+
+	for consumer := range topic.consumers {
+		if consumer.closed {
+			continue
+		}
+
+		select {
+		case consumer.events <- event:
+		default:
+			atomic.AddUint64(&consumer.dropped, 1)
+		}
+	}
+}
+
+func (h *StreamingHub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for key, topic := range h.topics {
+		for consumer := range topic.consumers {
+			if !consumer.closed {
+				consumer.closed = true
+				close(consumer.events)
+			}
+		}
+		delete(h.topics, key)
+	}
+}
@@ -0,0 +1,156 @@
+package sdk
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func runHub(t *testing.T, hub *StreamingHub) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = hub.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+// subscribeConsumers subscribes n consumers to candles of figi at once.
+func subscribeConsumers(hub *StreamingHub, figi string, n int) ([]*HubSubscription, []error) {
+	consumers := make([]*HubSubscription, n)
+	errs := make([]error, n)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			consumers[i], errs[i] = hub.SubscribeCandle(ctx, figi, CandleInterval1Min)
+		}(i)
+	}
+	wg.Wait()
+
+	return consumers, errs
+}
+
+func TestStreamingHubFanOut(t *testing.T) {
+	// consumers join pending subscription before its first event
+	srv := newFakeStreamingServer(t, 200*time.Millisecond)
+	hub := NewStreamingHub(srv.dial(t), 0)
+	runHub(t, hub)
+
+	const n = 10
+	consumers, errs := subscribeConsumers(hub, testFIGI, n)
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("consumer %d: %v", i, err)
+		}
+	}
+
+	for i, consumer := range consumers {
+		select {
+		case event := <-consumer.Events():
+			if e, ok := event.(CandleEvent); !ok || e.Candle.FIGI != testFIGI {
+				t.Fatalf("consumer %d got %+v", i, event)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("consumer %d got no event", i)
+		}
+	}
+	if got := srv.subscribes(); got != 1 {
+		t.Fatalf("server received %d subscribe requests, want 1", got)
+	}
+	for sub, count := range hub.Consumers() {
+		if count != n {
+			t.Fatalf("%+v has %d consumers, want %d", sub, count, n)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, consumer := range consumers {
+		wg.Add(1)
+		go func(consumer *HubSubscription) {
+			defer wg.Done()
+			if err := consumer.Close(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}(consumer)
+	}
+	wg.Wait()
+
+	// the request is sent, but server may not have read it yet
+	for deadline := time.Now().Add(5 * time.Second); srv.unsubscribes() == 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got := srv.unsubscribes(); got != 1 {
+		t.Fatalf("server received %d unsubscribe requests, want 1", got)
+	}
+	if consumers := hub.Consumers(); len(consumers) != 0 {
+		t.Fatalf("consumers are left after close: %+v", consumers)
+	}
+	for i, consumer := range consumers {
+		if _, ok := <-consumer.Events(); ok {
+			t.Fatalf("events of consumer %d aren't closed", i)
+		}
+	}
+}
+
+func TestStreamingHubRejected(t *testing.T) {
+	srv := newFakeStreamingServer(t, 50*time.Millisecond)
+	hub := NewStreamingHub(srv.dial(t), 0)
+	runHub(t, hub)
+
+	_, errs := subscribeConsumers(hub, testRejectedFIGI, 5)
+	for i, err := range errs {
+		if _, ok := err.(*StreamingError); !ok {
+			t.Fatalf("consumer %d: want *StreamingError, got %v", i, err)
+		}
+	}
+	if consumers := hub.Consumers(); len(consumers) != 0 {
+		t.Fatalf("consumers of failed subscription are left: %+v", consumers)
+	}
+}
+
+func TestStreamingHubRunClosesConsumers(t *testing.T) {
+	srv := newFakeStreamingServer(t, 0)
+	hub := NewStreamingHub(srv.dial(t), 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = hub.Run(ctx)
+	}()
+
+	consumers, errs := subscribeConsumers(hub, testFIGI, 3)
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("consumer %d: %v", i, err)
+		}
+	}
+
+	cancel()
+	<-done
+
+	for i, consumer := range consumers {
+		timeout := time.After(5 * time.Second)
+		for open := true; open; {
+			select {
+			case _, open = <-consumer.Events():
+			case <-timeout:
+				t.Fatalf("events of consumer %d aren't closed after Run", i)
+			}
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package sdk
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMetricsNamespace is prefix of metric names written by PrometheusMetrics.
+const DefaultMetricsNamespace = "tinkoff_invest_streaming"
+
+type (
+	// StreamingMetrics receives measurements of StreamingClient and StreamingPool.
+	// Implementations must be safe for concurrent use.
+	StreamingMetrics interface {
+		// MessageReceived is called for every decoded event. Subscription is name like candle:BBG005DXJS36:5min,
+		// it is empty for error events. Latency is difference between receive time and FullEvent.Time.
+		MessageReceived(event, subscription string, received time.Time, latency time.Duration)
+		// DecodeFailed is called for message which can't be decoded. Event is name of event from the message,
+		// e.g. name of unsupported event, it is empty if the message isn't JSON.
+		DecodeFailed(event string)
+		// Reconnected is called by StreamingPool when lost connection is replaced by new one.
+		// StreamingClient doesn't reconnect and never calls it.
+		Reconnected()
+		// SubscriptionRemoved is called on unsubscribe, per subscription metrics should be dropped.
+		SubscriptionRemoved(subscription string)
+	}
+
+	nopStreamingMetrics struct{}
+
+	// PrometheusMetrics collects StreamingMetrics and writes them in Prometheus text exposition format.
+	PrometheusMetrics struct {
+		namespace string
+		buckets   []float64
+
+		mu             sync.Mutex
+		messages       map[string]uint64
+		decodeFailures map[string]uint64
+		reconnects     uint64
+		lastMessage    map[string]time.Time
+		latency        map[string]*latencyHistogram
+	}
+
+	latencyHistogram struct {
+		buckets []uint64 // cumulative counts by buckets of PrometheusMetrics
+		count   uint64
+		sum     float64
+	}
+)
+
+var (
+	_ StreamingMetrics = nopStreamingMetrics{}
+	_ StreamingMetrics = &PrometheusMetrics{}
+	_ http.Handler     = &PrometheusMetrics{}
+)
+
+func (nopStreamingMetrics) MessageReceived(string, string, time.Time, time.Duration) {}
+func (nopStreamingMetrics) DecodeFailed(string)                                      {}
+func (nopStreamingMetrics) Reconnected()                                             {}
+func (nopStreamingMetrics) SubscriptionRemoved(string)                               {}
+
+// SetMetrics sets metrics receiver, it is safe to call it while connection is used, prefer WithStreamingMetrics option.
+func (c *StreamingClient) SetMetrics(metrics StreamingMetrics) {
+	c.settingsMu.Lock()
+	defer c.settingsMu.Unlock()
+
+	c.metrics = metrics
+}
+
+func (c *StreamingClient) currentMetrics() StreamingMetrics {
+	c.settingsMu.RLock()
+	defer c.settingsMu.RUnlock()
+
+	return c.metrics
+}
+
+// SetMetrics sets metrics receiver for the pool and connections opened after the call.
+func (p *StreamingPool) SetMetrics(metrics StreamingMetrics) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.metrics = metrics
+}
+
+func (c *StreamingClient) observeEvent(event interface{}, name string, received time.Time) {
+	var (
+		subscription string
+		exchangeTime time.Time
+	)
+
+	if key, ok := eventSubscriptionKey(event); ok {
+		subscription = key.String()
+	}
+
+	switch e := event.(type) {
+	case CandleEvent:
+		exchangeTime = e.Time
+	case OrderBookEvent:
+		exchangeTime = e.Time
+	case InstrumentInfoEvent:
+		exchangeTime = e.Time
+	case ErrorEvent:
+		exchangeTime = e.Time
+	}
+
+	var latency time.Duration
+	if !exchangeTime.IsZero() {
+		latency = received.Sub(exchangeTime)
+	}
+
+	c.currentMetrics().MessageReceived(name, subscription, received, latency)
+}
+
+// NewPrometheusMetrics returns metrics with names prefixed by namespace or DefaultMetricsNamespace if namespace is empty.
+func NewPrometheusMetrics(namespace string) *PrometheusMetrics {
+	if namespace == "" {
+		namespace = DefaultMetricsNamespace
+	}
+
+	return &PrometheusMetrics{
+		namespace:      namespace,
+		buckets:        latencyBuckets(),
+		messages:       make(map[string]uint64),
+		decodeFailures: make(map[string]uint64),
+		lastMessage:    make(map[string]time.Time),
+		latency:        make(map[string]*latencyHistogram),
+	}
+}
+
+// MessageReceived implements StreamingMetrics.
+func (m *PrometheusMetrics) MessageReceived(event, subscription string, received time.Time, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages[event]++
+	if subscription != "" {
+		m.lastMessage[subscription] = received
+	}
+
+	if latency <= 0 {
+		return
+	}
+
+	h, ok := m.latency[event]
+	if !ok {
+		h = &latencyHistogram{buckets: make([]uint64, len(m.buckets))}
+		m.latency[event] = h
+	}
+	h.observe(m.buckets, latency.Seconds())
+}
+
+// DecodeFailed implements StreamingMetrics.
+func (m *PrometheusMetrics) DecodeFailed(event string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.decodeFailures[event]++
+}
+
+// Reconnected implements StreamingMetrics.
+func (m *PrometheusMetrics) Reconnected() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reconnects++
+}
+
+// SubscriptionRemoved implements StreamingMetrics.
+func (m *PrometheusMetrics) SubscriptionRemoved(subscription string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.lastMessage, subscription)
+}
+
+// ServeHTTP writes metrics, so PrometheusMetrics may be used as scrape handler.
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	if err := m.WritePrometheus(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// WritePrometheus writes metrics in Prometheus text exposition format.
+func (m *PrometheusMetrics) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var b strings.Builder
+
+	name := m.namespace + "_messages_total"
+	fmt.Fprintf(&b, "# HELP %s Received messages by event type.\n# TYPE %s counter\n", name, name)
+	for _, event := range sortedKeys(m.messages) {
+		fmt.Fprintf(&b, "%s{event=%s} %d\n", name, labelValue(event), m.messages[event])
+	}
+
+	name = m.namespace + "_decode_failures_total"
+	fmt.Fprintf(&b, "# HELP %s Messages which can't be decoded.\n# TYPE %s counter\n", name, name)
+	for _, event := range sortedKeys(m.decodeFailures) {
+		fmt.Fprintf(&b, "%s{event=%s} %d\n", name, labelValue(event), m.decodeFailures[event])
+	}
+
+	name = m.namespace + "_reconnects_total"
+	fmt.Fprintf(&b, "# HELP %s Reconnects of lost connections.\n# TYPE %s counter\n", name, name)
+	fmt.Fprintf(&b, "%s %d\n", name, m.reconnects)
+
+	name = m.namespace + "_seconds_since_last_message"
+	fmt.Fprintf(&b, "# HELP %s Time since the last message by subscription.\n# TYPE %s gauge\n", name, name)
+	subscriptions := make([]string, 0, len(m.lastMessage))
+	for subscription := range m.lastMessage {
+		subscriptions = append(subscriptions, subscription)
+	}
+	sort.Strings(subscriptions)
+	for _, subscription := range subscriptions {
+		fmt.Fprintf(&b, "%s{subscription=%s} %g\n", name, labelValue(subscription), now.Sub(m.lastMessage[subscription]).Seconds())
+	}
+
+	name = m.namespace + "_latency_seconds"
+	fmt.Fprintf(&b, "# HELP %s Exchange-to-local latency by event type.\n# TYPE %s histogram\n", name, name)
+	events := make([]string, 0, len(m.latency))
+	for event := range m.latency {
+		events = append(events, event)
+	}
+	sort.Strings(events)
+	for _, event := range events {
+		h, label := m.latency[event], labelValue(event)
+		for i, le := range m.buckets {
+			fmt.Fprintf(&b, "%s_bucket{event=%s,le=\"%g\"} %d\n", name, label, le, h.buckets[i])
+		}
+		fmt.Fprintf(&b, "%s_bucket{event=%s,le=\"+Inf\"} %d\n", name, label, h.count)
+		fmt.Fprintf(&b, "%s_sum{event=%s} %g\n", name, label, h.sum)
+		fmt.Fprintf(&b, "%s_count{event=%s} %d\n", name, label, h.count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func (h *latencyHistogram) observe(bounds []float64, seconds float64) {
+	h.count++
+	h.sum += seconds
+
+	for i, le := range bounds {
+		if seconds <= le {
+			h.buckets[i]++
+		}
+	}
+}
+
+// latencyBuckets returns upper bounds in seconds of exchange-to-local latency histogram.
+func latencyBuckets() []float64 {
+	return []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+}
+
+// labelValue returns quoted label value escaped by text exposition format: backslash, double quote and line feed.
+func labelValue(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
+
+func sortedKeys(m map[string]uint64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
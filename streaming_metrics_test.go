@@ -0,0 +1,32 @@
+package sdk
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrometheusMetricsLabelEscaping(t *testing.T) {
+	m := NewPrometheusMetrics("test")
+	m.DecodeFailed("bad\"event\\\nname")
+	m.MessageReceived("candle", "candle:BBG005DXJS36:5min", time.Now(), 20*time.Millisecond)
+
+	var b strings.Builder
+	if err := m.WritePrometheus(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		`test_decode_failures_total{event="bad\"event\\\nname"} 1`,
+		`test_messages_total{event="candle"} 1`,
+		`test_latency_seconds_bucket{event="candle",le="0.025"} 1`,
+		`test_latency_seconds_bucket{event="candle",le="0.01"} 0`,
+		`test_latency_seconds_count{event="candle"} 1`,
+		`test_seconds_since_last_message{subscription="candle:BBG005DXJS36:5min"}`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output doesn't contain %s:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package sdk
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultHandshakeTimeout is default timeout of streaming connection handshake.
+const DefaultHandshakeTimeout = 5 * time.Second
+
+// StreamingOption configures StreamingClient before connection.
+type StreamingOption func(client *StreamingClient)
+
+// NewPingPongConfig returns enabled keepalive config: ping is sent every pingPeriod,
+// connection is considered lost if pong isn't received during pongWait. pingPeriod should be less than pongWait.
+func NewPingPongConfig(pingPeriod, pongWait time.Duration) *PingPongConfig {
+	return &PingPongConfig{isEnabled: true, pongWait: pongWait, pingPeriod: pingPeriod}
+}
+
+// WithStreamingURL connects to custom streaming api url, StreamingApiURL by default.
+func WithStreamingURL(apiURL string) StreamingOption {
+	return func(client *StreamingClient) {
+		client.apiURL = apiURL
+	}
+}
+
+// WithStreamingLogger replaces Printf logger passed to constructor, token is redacted from all records.
+func WithStreamingLogger(logger StructuredLogger) StreamingOption {
+	return func(client *StreamingClient) {
+		client.logger = logger
+	}
+}
+
+// WithStreamingDialer replaces websocket dialer, options of proxy, TLS, handshake timeout, read buffer
+// and compression applied after it modify copy of the dialer.
+func WithStreamingDialer(dialer *websocket.Dialer) StreamingOption {
+	return func(client *StreamingClient) {
+		client.dialer = *dialer
+	}
+}
+
+// WithStreamingProxy sets proxy of connection, proxy is taken from environment by default.
+func WithStreamingProxy(proxy func(*http.Request) (*url.URL, error)) StreamingOption {
+	return func(client *StreamingClient) {
+		client.dialer.Proxy = proxy
+	}
+}
+
+// WithStreamingTLSConfig sets TLS config of connection.
+func WithStreamingTLSConfig(config *tls.Config) StreamingOption {
+	return func(client *StreamingClient) {
+		client.dialer.TLSClientConfig = config
+	}
+}
+
+// WithStreamingHandshakeTimeout sets timeout of connection handshake, DefaultHandshakeTimeout by default.
+func WithStreamingHandshakeTimeout(timeout time.Duration) StreamingOption {
+	return func(client *StreamingClient) {
+		client.dialer.HandshakeTimeout = timeout
+	}
+}
+
+// WithStreamingKeepalive enables ping-pong: ping is sent every pingPeriod, read fails if pong isn't received
+// during pongWait. pingPeriod should be less than pongWait, e.g. DefaultPingPeriod and DefaultPongWait.
+func WithStreamingKeepalive(pingPeriod, pongWait time.Duration) StreamingOption {
+	return func(client *StreamingClient) {
+		client.pingPongCfg = NewPingPongConfig(pingPeriod, pongWait)
+	}
+}
+
+// WithStreamingReadBufferSize sets size of connection read buffer in bytes.
+func WithStreamingReadBufferSize(size int) StreamingOption {
+	return func(client *StreamingClient) {
+		client.dialer.ReadBufferSize = size
+	}
+}
+
+// WithStreamingCompression negotiates per message compression with server.
+func WithStreamingCompression(enabled bool) StreamingOption {
+	return func(client *StreamingClient) {
+		client.dialer.EnableCompression = enabled
+	}
+}
+
+// WithStreamingRecorder sets recorder for all raw messages received by read loop, see SetRecorder.
+func WithStreamingRecorder(recorder MessageRecorder) StreamingOption {
+	return func(client *StreamingClient) {
+		client.recorder = recorder
+	}
+}
+
+// WithStreamingMetrics sets metrics receiver, see SetMetrics.
+func WithStreamingMetrics(metrics StreamingMetrics) StreamingOption {
+	return func(client *StreamingClient) {
+		client.metrics = metrics
+	}
+}
+
+// WithStreamingTracer sets tracer of subscription requests and received events, see SetTracer.
+func WithStreamingTracer(tracer Tracer) StreamingOption {
+	return func(client *StreamingClient) {
+		client.tracer = tracer
+	}
+}
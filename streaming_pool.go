@@ -0,0 +1,496 @@
+package sdk
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultMaxSubscriptionsPerConn is default cap of subscriptions per connection of StreamingPool.
+const DefaultMaxSubscriptionsPerConn = 300
+
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+var (
+	ErrPoolRunning = errors.New("streaming pool is already running")
+	ErrPoolClosed  = errors.New("streaming pool is closed")
+
+	errShardNotNeeded = errors.New("connection isn't needed")
+)
+
+type (
+	// StreamingDialer opens new streaming connection for StreamingPool.
+	StreamingDialer func(ctx context.Context) (*StreamingClient, error)
+
+	// StreamingPool spreads subscriptions across several streaming connections with per connection cap
+	// and presents single merged event stream. Lost connections are reconnected by Run and their subscriptions
+	// are rebalanced over connections with spare capacity.
+	StreamingPool struct {
+		dial       StreamingDialer
+		maxPerConn int
+		logger     StructuredLogger
+		metrics    StreamingMetrics
+		// clientLogger is set to dialed connections, nil means their own loggers are kept.
+		clientLogger StructuredLogger
+		tracer       Tracer
+
+		mu      sync.Mutex
+		shards  []*poolShard
+		subs    map[subscriptionKey]*poolShard
+		running bool
+		runCtx  context.Context
+		// closed is set by Close, connections aren't opened after it.
+		closed  bool
+		closing chan struct{}
+
+		events   chan poolEvent
+		failures chan shardFailure
+		wg       sync.WaitGroup
+	}
+
+	// ShardStats contains subscription count of one pool connection.
+	ShardStats struct {
+		Subscriptions int
+	}
+
+	poolShard struct {
+		client *StreamingClient
+		subs   map[subscriptionKey]Subscription
+	}
+
+	poolEvent struct {
+		shard *poolShard
+		event interface{}
+	}
+
+	shardFailure struct {
+		shard *poolShard
+		err   error
+	}
+)
+
+// NewStreamingPool returns pool which opens connections by dial when existing ones are full.
+// Cap is maxPerConn or DefaultMaxSubscriptionsPerConn if maxPerConn <= 0.
+func NewStreamingPool(logger Logger, dial StreamingDialer, maxPerConn int) *StreamingPool {
+	if maxPerConn <= 0 {
+		maxPerConn = DefaultMaxSubscriptionsPerConn
+	}
+
+	return &StreamingPool{
+		dial:       dial,
+		maxPerConn: maxPerConn,
+		logger:     NewPrintfLogger(logger, LogInfo),
+		subs:       make(map[subscriptionKey]*poolShard),
+		closing:    make(chan struct{}),
+		events:     make(chan poolEvent),
+		failures:   make(chan shardFailure),
+	}
+}
+
+// Run reads events of all connections and calls fn for every event from single goroutine
+// until fn error, ctx cancellation or Close. Lost connections are reconnected and resubscribed in background,
+// so events of other connections are delivered during reconnection backoff.
+// Run returns ErrPoolRunning if it is already running and ErrPoolClosed if the pool is closed.
+// Connections can't be read after Run returns, so the pool should be closed.
+func (p *StreamingPool) Run(ctx context.Context, fn func(event interface{}) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+
+	p.mu.Lock()
+	switch {
+	case p.closed:
+		p.mu.Unlock()
+		cancel()
+		return ErrPoolClosed
+	case p.running:
+		p.mu.Unlock()
+		cancel()
+		return ErrPoolRunning
+	}
+	p.running = true
+	p.runCtx = ctx
+	for _, shard := range p.shards {
+		p.startShard(ctx, shard)
+	}
+	p.mu.Unlock()
+
+	defer func() {
+		// connections dialed after this point aren't read, so wg isn't changed during Wait
+		p.mu.Lock()
+		p.running = false
+		p.runCtx = nil
+		p.mu.Unlock()
+
+		cancel()
+		p.wg.Wait()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.closing:
+			return ErrPoolClosed
+		case e := <-p.events:
+			if errEvent, ok := e.event.(ErrorEvent); ok {
+				p.forgetFailed(e.shard, errEvent.Error.RequestID)
+			}
+			if err := fn(e.event); err != nil {
+				return err
+			}
+		case f := <-p.failures:
+			p.wg.Add(1)
+			go func() {
+				defer p.wg.Done()
+				p.reconnect(ctx, f.shard, f.err)
+			}()
+		}
+	}
+}
+
+// SubscribeCandle subscribes to candles on connection with spare capacity, subscription errors are delivered as ErrorEvent.
+func (p *StreamingPool) SubscribeCandle(ctx context.Context, figi string, interval CandleInterval) error {
+	return p.subscribe(ctx, candleSubscription(figi, interval, ""))
+}
+
+// UnsubscribeCandle unsubscribes from candles.
+func (p *StreamingPool) UnsubscribeCandle(ctx context.Context, figi string, interval CandleInterval) error {
+	return p.unsubscribe(ctx, candleSubscription(figi, interval, ""))
+}
+
+// SubscribeOrderbook subscribes to orderbook on connection with spare capacity, subscription errors are delivered as ErrorEvent.
+func (p *StreamingPool) SubscribeOrderbook(ctx context.Context, figi string, depth int) error {
+	if depth < 1 || depth > MaxOrderbookDepth {
+		return ErrDepth
+	}
+
+	return p.subscribe(ctx, orderbookSubscription(figi, depth, ""))
+}
+
+// UnsubscribeOrderbook unsubscribes from orderbook.
+func (p *StreamingPool) UnsubscribeOrderbook(ctx context.Context, figi string, depth int) error {
+	return p.unsubscribe(ctx, orderbookSubscription(figi, depth, ""))
+}
+
+// SubscribeInstrumentInfo subscribes to instrument info on connection with spare capacity, subscription errors are delivered as ErrorEvent.
+func (p *StreamingPool) SubscribeInstrumentInfo(ctx context.Context, figi string) error {
+	return p.subscribe(ctx, instrumentInfoSubscription(figi, ""))
+}
+
+// UnsubscribeInstrumentInfo unsubscribes from instrument info.
+func (p *StreamingPool) UnsubscribeInstrumentInfo(ctx context.Context, figi string) error {
+	return p.unsubscribe(ctx, instrumentInfoSubscription(figi, ""))
+}
+
+// Stats returns subscription count of every connection.
+func (p *StreamingPool) Stats() []ShardStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make([]ShardStats, 0, len(p.shards))
+	for _, shard := range p.shards {
+		stats = append(stats, ShardStats{Subscriptions: len(shard.subs)})
+	}
+
+	return stats
+}
+
+// SetLogger sets logger for the pool and connections opened after the call.
+func (p *StreamingPool) SetLogger(logger StructuredLogger) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.logger = logger
+	p.clientLogger = logger
+}
+
+// Close closes all connections, Run returns ErrPoolClosed and new connections aren't opened after it.
+// It is safe to call Close several times.
+func (p *StreamingPool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.closing)
+	shards := p.shards
+	p.shards = nil
+	p.subs = make(map[subscriptionKey]*poolShard)
+	p.mu.Unlock()
+
+	var closeErr error
+	for _, shard := range shards {
+		if err := shard.client.Close(); err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+
+	return closeErr
+}
+
+func (p *StreamingPool) subscribe(ctx context.Context, sub Subscription) error {
+	if err := validateSubscription(sub); err != nil {
+		return err
+	}
+
+	return p.assign(ctx, sub)
+}
+
+// unsubscribe sends request without mu lock, subscription keeps its slot until request succeeds.
+func (p *StreamingPool) unsubscribe(ctx context.Context, sub Subscription) error {
+	key := subscriptionKeyOf(sub)
+
+	p.mu.Lock()
+	shard, ok := p.subs[key]
+	p.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	if err := shard.client.unsubscribe(ctx, sub); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.subs[key] == shard {
+		delete(shard.subs, key)
+		delete(p.subs, key)
+	}
+
+	return nil
+}
+
+// assign subscribes on the least loaded connection under cap, new connection is opened if all are full.
+// Slot is reserved under mu lock and request is sent without it, the reservation is rolled back if request fails.
+// Connection is dialed without mu lock, so capacity is checked again after dial.
+func (p *StreamingPool) assign(ctx context.Context, sub Subscription) error {
+	key := subscriptionKeyOf(sub)
+
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return ErrPoolClosed
+		}
+		if _, ok := p.subs[key]; ok {
+			p.mu.Unlock()
+			return nil
+		}
+
+		if shard := p.leastLoaded(); shard != nil {
+			shard.subs[key] = sub
+			p.subs[key] = shard
+			p.mu.Unlock()
+
+			return p.send(ctx, shard, sub)
+		}
+		poolLogger, metrics, logger, tracer := p.logger, p.metrics, p.clientLogger, p.tracer
+		p.mu.Unlock()
+
+		client, err := p.dial(ctx)
+		if err != nil {
+			return errors.Wrap(err, "can't open streaming connection")
+		}
+
+		if metrics != nil {
+			client.SetMetrics(metrics)
+		}
+		if logger != nil {
+			client.SetLogger(logger)
+		}
+		if tracer != nil {
+			client.SetTracer(tracer)
+		}
+
+		if err := p.addShard(client); err != nil {
+			if closeErr := client.Close(); closeErr != nil {
+				poolLogger.Log(LogWarn, "Can't close unused connection", Field{Key: "error", Value: closeErr})
+			}
+			if err == ErrPoolClosed {
+				return err
+			}
+		}
+	}
+}
+
+// send subscribes on connection with reserved slot and releases the slot on failure.
+// Slot which has been taken by reconnect or failed subscription during request isn't touched.
+func (p *StreamingPool) send(ctx context.Context, shard *poolShard, sub Subscription) error {
+	err := shard.client.subscribe(ctx, sub, false)
+	if err == nil {
+		return nil
+	}
+
+	key := subscriptionKeyOf(sub)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.subs[key] == shard {
+		delete(shard.subs, key)
+		delete(p.subs, key)
+	}
+
+	return err
+}
+
+// addShard adds dialed connection to the pool, errShardNotNeeded is returned if other connection
+// has got spare capacity during dial and ErrPoolClosed if the pool is closed.
+func (p *StreamingPool) addShard(client *StreamingClient) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrPoolClosed
+	}
+	if p.leastLoaded() != nil {
+		return errShardNotNeeded
+	}
+
+	shard := &poolShard{client: client, subs: make(map[subscriptionKey]Subscription)}
+	p.shards = append(p.shards, shard)
+	if p.running {
+		p.startShard(p.runCtx, shard)
+	}
+
+	return nil
+}
+
+// leastLoaded returns connection with the least subscriptions under cap or nil if all are full.
+// Must be called under mu lock.
+func (p *StreamingPool) leastLoaded() *poolShard {
+	var shard *poolShard
+	for _, s := range p.shards {
+		if len(s.subs) < p.maxPerConn && (shard == nil || len(s.subs) < len(shard.subs)) {
+			shard = s
+		}
+	}
+
+	return shard
+}
+
+// startShard runs read loop of connection, must be called under mu lock.
+func (p *StreamingPool) startShard(ctx context.Context, shard *poolShard) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		err := shard.client.RunReadLoopContext(ctx, func(event interface{}) error {
+			select {
+			case p.events <- poolEvent{shard: shard, event: event}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		select {
+		case p.failures <- shardFailure{shard: shard, err: err}:
+		case <-ctx.Done():
+		}
+	}()
+}
+
+// forgetFailed removes subscription failed by server, so it isn't resubscribed after reconnect.
+func (p *StreamingPool) forgetFailed(shard *poolShard, requestID string) {
+	sub, ok := shard.client.SubscriptionByRequestID(requestID)
+	if !ok {
+		return
+	}
+	key := subscriptionKeyOf(sub)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.subs[key] == shard {
+		delete(shard.subs, key)
+		delete(p.subs, key)
+	}
+}
+
+// reconnect removes lost connection and assigns its subscriptions to connections with spare capacity
+// or to new connections, retrying with backoff until success, ctx cancellation or Close.
+func (p *StreamingPool) reconnect(ctx context.Context, lost *poolShard, cause error) {
+	p.mu.Lock()
+	for i, shard := range p.shards {
+		if shard == lost {
+			p.shards = append(p.shards[:i], p.shards[i+1:]...)
+			break
+		}
+	}
+	orphans := make([]Subscription, 0, len(lost.subs))
+	for key, sub := range lost.subs {
+		if p.subs[key] == lost {
+			delete(p.subs, key)
+		}
+		orphans = append(orphans, sub)
+	}
+	lost.subs = make(map[subscriptionKey]Subscription)
+	p.logger.Log(LogWarn, "Streaming connection is lost, rebalancing subscriptions",
+		Field{Key: "subscriptions", Value: len(orphans)},
+		Field{Key: "error", Value: cause},
+	)
+	logger := p.logger
+	p.mu.Unlock()
+
+	if err := lost.client.Close(); err != nil {
+		logger.Log(LogWarn, "Can't close lost connection", Field{Key: "error", Value: err})
+	}
+
+	delay := minReconnectDelay
+	for {
+		orphans = p.reassign(ctx, orphans)
+		if len(orphans) == 0 {
+			p.mu.Lock()
+			metrics := p.metrics
+			p.mu.Unlock()
+
+			if metrics != nil {
+				metrics.Reconnected()
+			}
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.closing:
+			return
+		case <-time.After(delay):
+		}
+
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// reassign subscribes orphans and returns the ones which can't be subscribed now.
+func (p *StreamingPool) reassign(ctx context.Context, orphans []Subscription) []Subscription {
+	for i, sub := range orphans {
+		sub.RequestID = ""
+		if err := p.assign(ctx, sub); err != nil {
+			if err != ErrPoolClosed && ctx.Err() == nil {
+				p.mu.Lock()
+				p.logger.Log(LogWarn, "Can't resubscribe",
+					Field{Key: "subscription", Value: subscriptionKeyOf(sub).String()},
+					Field{Key: "error", Value: err},
+				)
+				p.mu.Unlock()
+			}
+			return orphans[i:]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,246 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func newTestPool(t *testing.T, srv *fakeStreamingServer, maxPerConn int) *StreamingPool {
+	t.Helper()
+
+	pool := NewStreamingPool(nil, func(ctx context.Context) (*StreamingClient, error) {
+		return NewStreamingClientContext(ctx, nil, "token", WithStreamingURL(srv.url()))
+	}, maxPerConn)
+	t.Cleanup(func() { pool.Close() })
+
+	return pool
+}
+
+// runPool runs pool until the test ends and returns channel of candle events and channel of Run result.
+func runPool(t *testing.T, pool *StreamingPool) (<-chan CandleEvent, <-chan error) {
+	t.Helper()
+
+	candles := make(chan CandleEvent, 1024)
+	result := make(chan error, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		result <- pool.Run(ctx, func(event interface{}) error {
+			if e, ok := event.(CandleEvent); ok {
+				select {
+				case candles <- e:
+				default:
+				}
+			}
+			return nil
+		})
+	}()
+	t.Cleanup(cancel)
+
+	return candles, result
+}
+
+func testPoolFIGI(i int) string {
+	return fmt.Sprintf("BBG%09d", i+1)
+}
+
+// waitCandles waits for candles of n distinct instruments.
+func waitCandles(t *testing.T, candles <-chan CandleEvent, n int) {
+	t.Helper()
+
+	seen := make(map[string]bool)
+	timeout := time.After(10 * time.Second)
+	for len(seen) < n {
+		select {
+		case e := <-candles:
+			seen[e.Candle.FIGI] = true
+		case <-timeout:
+			t.Fatalf("got candles of %d instruments, want %d", len(seen), n)
+		}
+	}
+}
+
+func TestStreamingPoolConcurrentSubscribe(t *testing.T) {
+	srv := newFakeStreamingServer(t, 0)
+	pool := newTestPool(t, srv, 3)
+	candles, _ := runPool(t, pool)
+
+	const n = 20
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := pool.SubscribeCandle(context.Background(), testPoolFIGI(i), CandleInterval1Min); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	waitCandles(t, candles, n)
+
+	var total int
+	for _, stats := range pool.Stats() {
+		if stats.Subscriptions > 3 {
+			t.Fatalf("connection has %d subscriptions over cap", stats.Subscriptions)
+		}
+		total += stats.Subscriptions
+	}
+	if total != n {
+		t.Fatalf("pool has %d subscriptions, want %d", total, n)
+	}
+}
+
+func TestStreamingPoolReconnect(t *testing.T) {
+	srv := newFakeStreamingServer(t, 0)
+	pool := newTestPool(t, srv, 2)
+	candles, _ := runPool(t, pool)
+
+	const n = 4
+	for i := 0; i < n; i++ {
+		if err := pool.SubscribeCandle(context.Background(), testPoolFIGI(i), CandleInterval1Min); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitCandles(t, candles, n)
+
+	dials := atomic.LoadInt32(&srv.dials)
+	srv.dropAll()
+	waitCandles(t, candles, n)
+
+	if got := atomic.LoadInt32(&srv.dials); got <= dials {
+		t.Fatalf("connections aren't reopened, dials %d", got)
+	}
+	// subscription may be moved to connection which is lost too, so it may be resubscribed twice
+	if got := srv.subscribes(); got < 2*n {
+		t.Fatalf("server received %d subscribe requests, want at least %d", got, 2*n)
+	}
+}
+
+func TestStreamingPoolCloseWhileRunning(t *testing.T) {
+	srv := newFakeStreamingServer(t, 0)
+	pool := newTestPool(t, srv, 1)
+	candles, result := runPool(t, pool)
+
+	for i := 0; i < 3; i++ {
+		if err := pool.SubscribeCandle(context.Background(), testPoolFIGI(i), CandleInterval1Min); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitCandles(t, candles, 3)
+
+	if err := pool.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-result:
+		if err != ErrPoolClosed {
+			t.Fatalf("want ErrPoolClosed, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run isn't finished after Close")
+	}
+
+	dials := atomic.LoadInt32(&srv.dials)
+	if err := pool.SubscribeCandle(context.Background(), testPoolFIGI(3), CandleInterval1Min); err != ErrPoolClosed {
+		t.Fatalf("want ErrPoolClosed, got %v", err)
+	}
+	if err := pool.Run(context.Background(), func(interface{}) error { return nil }); err != ErrPoolClosed {
+		t.Fatalf("want ErrPoolClosed, got %v", err)
+	}
+	if got := atomic.LoadInt32(&srv.dials); got != dials {
+		t.Fatalf("connection is opened after Close")
+	}
+	if err := pool.Close(); err != nil {
+		t.Fatalf("second close: %v", err)
+	}
+}
+
+// stallingTracer blocks sending of subscription requests of figi until release is closed or request ctx is done.
+type stallingTracer struct {
+	nopTracer
+	figi    string
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *stallingTracer) StartMessage(ctx context.Context, info MessageInfo) (context.Context, MessageSpan) {
+	if info.Direction == MessageSent && info.FIGI == s.figi {
+		s.entered <- struct{}{}
+		select {
+		case <-s.release:
+		case <-ctx.Done():
+		}
+	}
+
+	return ctx, nopMessageSpan{}
+}
+
+func TestStreamingPoolSendWithoutLock(t *testing.T) {
+	srv := newFakeStreamingServer(t, 0)
+	pool := newTestPool(t, srv, 2)
+	tracer := &stallingTracer{figi: testPoolFIGI(0), entered: make(chan struct{}, 1), release: make(chan struct{})}
+	pool.SetTracer(tracer)
+
+	stalled := make(chan error, 1)
+	go func() {
+		stalled <- pool.SubscribeCandle(context.Background(), testPoolFIGI(0), CandleInterval1Min)
+	}()
+	<-tracer.entered
+
+	// the stalled request holds reserved slot, pool isn't locked by it
+	if err := pool.SubscribeCandle(context.Background(), testPoolFIGI(1), CandleInterval1Min); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.SubscribeCandle(context.Background(), testPoolFIGI(2), CandleInterval1Min); err != nil {
+		t.Fatal(err)
+	}
+	if stats := pool.Stats(); len(stats) != 2 || stats[0].Subscriptions != 2 || stats[1].Subscriptions != 1 {
+		t.Fatalf("stats %+v, want reserved slot to be counted", stats)
+	}
+	if err := pool.UnsubscribeCandle(context.Background(), testPoolFIGI(2), CandleInterval1Min); err != nil {
+		t.Fatal(err)
+	}
+
+	close(tracer.release)
+	if err := <-stalled; err != nil {
+		t.Fatal(err)
+	}
+	if stats := pool.Stats(); stats[0].Subscriptions != 2 || stats[1].Subscriptions != 0 {
+		t.Fatalf("stats %+v after unsubscribe", stats)
+	}
+}
+
+func TestStreamingPoolFailedSendReleasesSlot(t *testing.T) {
+	srv := newFakeStreamingServer(t, 0)
+	pool := newTestPool(t, srv, 1)
+	tracer := &stallingTracer{figi: testPoolFIGI(0), entered: make(chan struct{}, 1), release: make(chan struct{})}
+	pool.SetTracer(tracer)
+
+	failed := make(chan error, 1)
+	go func() {
+		failed <- pool.SubscribeCandle(context.Background(), testPoolFIGI(0), CandleInterval1Min)
+	}()
+	<-tracer.entered
+
+	// connection closed during request fails it
+	pool.mu.Lock()
+	client := pool.shards[0].client
+	pool.mu.Unlock()
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+	close(tracer.release)
+	if err := <-failed; errors.Cause(err) != ErrStreamingClosed {
+		t.Fatalf("want ErrStreamingClosed, got %v", err)
+	}
+	if stats := pool.Stats(); len(stats) != 1 || stats[0].Subscriptions != 0 {
+		t.Fatalf("stats %+v, want slot to be released", stats)
+	}
+
+}
@@ -0,0 +1,327 @@
+package sdk
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ReplayAsFastAsPossible speed for StreamReplayer.Run which ignores original message timing.
+const ReplayAsFastAsPossible = 0
+
+// recorderFlushPeriod is max time recorded messages may stay in recorder buffers.
+const recorderFlushPeriod = time.Second
+
+// recordHeaderSize is size of record header: receive time in unix nanoseconds and message length.
+const recordHeaderSize = 12
+
+// maxRecordSize is limit of message length, it is far above size of any streaming message,
+// so larger length in record header means corrupted file.
+const maxRecordSize = 16 << 20
+
+var (
+	ErrRecorderClosed = errors.New("recorder closed")
+	ErrRecordTooLarge = errors.New("record is too large")
+)
+
+// MessageRecorder receives every raw message read by StreamingClient.
+type MessageRecorder interface {
+	Record(received time.Time, msg []byte) error
+}
+
+// RecordedMessage raw message with its receive time.
+type RecordedMessage struct {
+	Received time.Time
+	Message  []byte
+}
+
+var _ MessageRecorder = &StreamRecorder{}
+
+// StreamRecorder writes messages to gzip compressed stream of records:
+// 8 bytes receive time in unix nanoseconds, 4 bytes message length (big endian) and the message itself.
+// Every recorder session is separate gzip member, so the file may be appended by several sessions.
+type StreamRecorder struct {
+	mu     sync.Mutex
+	closer io.Closer
+	buf    *bufio.Writer
+	zw     *gzip.Writer
+	dirty  bool // messages are recorded since the last flush
+	closed bool
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewStreamRecorder returns recorder which writes to w, it must be closed to stop background flushing.
+func NewStreamRecorder(w io.Writer) *StreamRecorder {
+	zw := gzip.NewWriter(w)
+
+	r := &StreamRecorder{
+		zw:   zw,
+		buf:  bufio.NewWriter(zw),
+		done: make(chan struct{}),
+	}
+
+	r.wg.Add(1)
+	go r.flushLoop()
+
+	return r
+}
+
+// OpenStreamRecorder returns recorder which appends to file by path, file is created if it doesn't exist.
+func OpenStreamRecorder(path string) (*StreamRecorder, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't open record file %s", path)
+	}
+
+	r := NewStreamRecorder(f)
+	r.closer = f
+
+	return r, nil
+}
+
+// Record implements MessageRecorder. Buffered messages are flushed at least once a second until Close.
+// Messages longer than 16 MiB aren't recorded, ErrRecordTooLarge is returned for them.
+func (r *StreamRecorder) Record(received time.Time, msg []byte) error {
+	if len(msg) > maxRecordSize {
+		return errors.Wrapf(ErrRecordTooLarge, "message of %d bytes", len(msg))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return ErrRecorderClosed
+	}
+
+	var header [recordHeaderSize]byte
+	binary.BigEndian.PutUint64(header[:8], uint64(received.UnixNano()))
+	binary.BigEndian.PutUint32(header[8:], uint32(len(msg)))
+
+	if _, err := r.buf.Write(header[:]); err != nil {
+		return errors.Wrap(err, "can't write record header")
+	}
+	if _, err := r.buf.Write(msg); err != nil {
+		return errors.Wrap(err, "can't write record message")
+	}
+	r.dirty = true
+
+	return nil
+}
+
+// Flush writes buffered messages to underlying writer.
+func (r *StreamRecorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return ErrRecorderClosed
+	}
+
+	return r.flush()
+}
+
+// Close flushes buffered messages, finishes gzip stream and closes the file opened by OpenStreamRecorder.
+func (r *StreamRecorder) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	r.mu.Unlock()
+
+	close(r.done)
+	r.wg.Wait()
+
+	// writers aren't used by other goroutines after closed is set and flush loop is stopped
+	if err := r.buf.Flush(); err != nil {
+		return errors.Wrap(err, "can't flush recorder")
+	}
+	if err := r.zw.Close(); err != nil {
+		return errors.Wrap(err, "can't close gzip stream")
+	}
+	if r.closer != nil {
+		return r.closer.Close()
+	}
+
+	return nil
+}
+
+// flushLoop flushes recorded messages every recorderFlushPeriod until Close.
+// Write errors are sticky, so error of background flush is returned by the next Record.
+func (r *StreamRecorder) flushLoop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(recorderFlushPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			r.mu.Lock()
+			if r.dirty && !r.closed {
+				_ = r.flush()
+			}
+			r.mu.Unlock()
+		}
+	}
+}
+
+func (r *StreamRecorder) flush() error {
+	r.dirty = false
+
+	if err := r.buf.Flush(); err != nil {
+		return errors.Wrap(err, "can't flush recorder")
+	}
+	if err := r.zw.Flush(); err != nil {
+		return errors.Wrap(err, "can't flush gzip stream")
+	}
+
+	return nil
+}
+
+// StreamReplayer reads messages written by StreamRecorder and feeds them through the same decoding as RunReadLoop.
+type StreamReplayer struct {
+	logger StructuredLogger
+	closer io.Closer
+	zr     *gzip.Reader
+	r      *bufio.Reader
+}
+
+// NewStreamReplayer returns replayer which reads records from r.
+func NewStreamReplayer(logger Logger, r io.Reader) (*StreamReplayer, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't open gzip stream")
+	}
+
+	return &StreamReplayer{logger: NewPrintfLogger(logger, LogInfo), zr: zr, r: bufio.NewReader(zr)}, nil
+}
+
+// OpenStreamReplayer returns replayer which reads records from file by path.
+func OpenStreamReplayer(logger Logger, path string) (*StreamReplayer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't open record file %s", path)
+	}
+
+	p, err := NewStreamReplayer(logger, f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	p.closer = f
+
+	return p, nil
+}
+
+// Next returns next recorded message, io.EOF is returned at the end of records.
+// Record truncated by interrupted recording is treated as the end of records,
+// ErrRecordTooLarge is returned if message length in record header is over 16 MiB.
+func (p *StreamReplayer) Next() (RecordedMessage, error) {
+	var header [recordHeaderSize]byte
+	if _, err := io.ReadFull(p.r, header[:]); err != nil {
+		return RecordedMessage{}, p.endOfRecords(err)
+	}
+
+	size := binary.BigEndian.Uint32(header[8:])
+	if size > maxRecordSize {
+		return RecordedMessage{}, errors.Wrapf(ErrRecordTooLarge, "record of %d bytes", size)
+	}
+
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(p.r, msg); err != nil {
+		return RecordedMessage{}, p.endOfRecords(err)
+	}
+
+	return RecordedMessage{
+		Received: time.Unix(0, int64(binary.BigEndian.Uint64(header[:8]))),
+		Message:  msg,
+	}, nil
+}
+
+// Run decodes recorded messages and calls fn for every event until the end of records, fn error or ctx cancellation.
+// Speed 1 keeps original timing between messages, speed 10 replays ten times faster,
+// ReplayAsFastAsPossible doesn't wait at all.
+func (p *StreamReplayer) Run(ctx context.Context, speed float64, fn func(event interface{}) error) error {
+	var first time.Time
+	start := time.Now()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+
+	for {
+		rec, err := p.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if speed > 0 {
+			if first.IsZero() {
+				first = rec.Received
+			}
+
+			wait := time.Until(start.Add(time.Duration(float64(rec.Received.Sub(first)) / speed)))
+			if wait > 0 {
+				timer.Reset(wait)
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-timer.C:
+				}
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		event, name, err := decodeEvent(rec.Message)
+		if err != nil {
+			logDecodeError(p.logger, name, rec.Message, err)
+			continue
+		}
+
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+}
+
+// Close closes the file opened by OpenStreamReplayer.
+func (p *StreamReplayer) Close() error {
+	if err := p.zr.Close(); err != nil {
+		return errors.Wrap(err, "can't close gzip stream")
+	}
+	if p.closer != nil {
+		return p.closer.Close()
+	}
+
+	return nil
+}
+
+func (p *StreamReplayer) endOfRecords(err error) error {
+	switch err {
+	case io.EOF:
+		return io.EOF
+	case io.ErrUnexpectedEOF:
+		p.logger.Log(LogWarn, "Record file is truncated, replay is stopped")
+		return io.EOF
+	default:
+		return errors.Wrap(err, "can't read record")
+	}
+}
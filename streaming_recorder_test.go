@@ -0,0 +1,114 @@
+package sdk
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is buffer written by flush loop of recorder and read by test.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]byte(nil), b.buf.Bytes()...)
+}
+
+func TestStreamRecorderReplay(t *testing.T) {
+	var buf bytes.Buffer
+	recorder := NewStreamRecorder(&buf)
+
+	msg := ackMessage(subscriptionRequest{Event: "candle:subscribe", FIGI: testFIGI, Interval: CandleInterval1Min})
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := recorder.Record(start.Add(time.Duration(i)*time.Millisecond), msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.Record(start, msg); !errors.Is(err, ErrRecorderClosed) {
+		t.Fatalf("want ErrRecorderClosed, got %v", err)
+	}
+
+	replayer, err := NewStreamReplayer(nil, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer replayer.Close()
+
+	var candles int
+	err = replayer.Run(context.Background(), ReplayAsFastAsPossible, func(event interface{}) error {
+		if e, ok := event.(CandleEvent); ok && e.Candle.FIGI == testFIGI {
+			candles++
+		}
+		return nil
+	})
+	if err != nil || candles != 3 {
+		t.Fatalf("replayed %d candles, %v", candles, err)
+	}
+}
+
+func TestStreamRecorderBackgroundFlush(t *testing.T) {
+	buf := &syncBuffer{}
+	recorder := NewStreamRecorder(buf)
+	defer recorder.Close()
+
+	if err := recorder.Record(time.Now(), []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(3 * recorderFlushPeriod)
+	for {
+		replayer, err := NewStreamReplayer(nil, bytes.NewReader(buf.Bytes()))
+		if err == nil {
+			rec, err := replayer.Next()
+			if err == nil && string(rec.Message) == `{}` {
+				return
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("recorded message isn't flushed without new messages")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestStreamReplayerRecordTooLarge(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	var header [recordHeaderSize]byte
+	binary.BigEndian.PutUint32(header[8:], 1<<31)
+	if _, err := zw.Write(header[:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	replayer, err := NewStreamReplayer(nil, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := replayer.Next(); !errors.Is(err, ErrRecordTooLarge) {
+		t.Fatalf("want ErrRecordTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,401 @@
+package sdk
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+var ErrUnsubscribed = errors.New("unsubscribed before acknowledgement")
+
+var (
+	ErrInvalidFIGI     = errors.New("invalid figi, should be 12 characters of A-Z and 0-9")
+	ErrInvalidInterval = errors.New("invalid candle interval")
+)
+
+// figiLength is length of Financial Instrument Global Identifier.
+const figiLength = 12
+
+type SubscriptionStatus string
+
+const (
+	// SubscriptionPending request is sent, neither data nor error is received yet.
+	SubscriptionPending SubscriptionStatus = "Pending"
+	// SubscriptionActive data is received for subscription.
+	SubscriptionActive SubscriptionStatus = "Active"
+	// SubscriptionFailed error event is received for subscription request or connection is lost before acknowledgement.
+	SubscriptionFailed SubscriptionStatus = "Failed"
+)
+
+// Subscription describes subscription made by StreamingClient.
+type Subscription struct {
+	RequestID string
+	Event     string // candle, orderbook or instrument_info
+	FIGI      string
+	Interval  CandleInterval // candle only
+	Depth     int            // orderbook only
+	Status    SubscriptionStatus
+	Err       error // set for failed subscription
+}
+
+type subscriptionKey struct {
+	event    string
+	figi     string
+	interval CandleInterval
+	depth    int
+}
+
+type subscriptionState struct {
+	sub Subscription
+	ack chan struct{} // closed when subscription leaves pending status
+}
+
+// Subscriptions returns pending, active and failed subscriptions. Unsubscribed ones are removed from the table.
+func (c *StreamingClient) Subscriptions() []Subscription {
+	c.subsMu.Lock()
+	defer c.subsMu.Unlock()
+
+	subs := make([]Subscription, 0, len(c.subs))
+	for _, state := range c.subs {
+		subs = append(subs, state.sub)
+	}
+
+	return subs
+}
+
+// SubscriptionByRequestID returns subscription by request id, use it to find subscription failed by ErrorEvent.
+func (c *StreamingClient) SubscriptionByRequestID(requestID string) (Subscription, bool) {
+	c.subsMu.Lock()
+	defer c.subsMu.Unlock()
+
+	key, ok := c.requests[requestID]
+	if !ok {
+		return Subscription{}, false
+	}
+
+	return c.subs[key].sub, true
+}
+
+// newRequestID returns request id unique within the client.
+func (c *StreamingClient) newRequestID() string {
+	return c.requestIDPrefix + "-" + strconv.FormatUint(atomic.AddUint64(&c.requestSeq, 1), 10)
+}
+
+// subscribe registers pending subscription, sends request and waits for acknowledgement if wait is set:
+// the first event for the subscription or error event correlated by request id.
+// If the same subscription is already pending, request isn't sent again and its acknowledgement is awaited.
+func (c *StreamingClient) subscribe(ctx context.Context, sub Subscription, wait bool) error {
+	state, err := c.startSubscription(ctx, sub)
+	if err != nil {
+		return err
+	}
+
+	if !wait {
+		return nil
+	}
+
+	return c.waitSubscription(ctx, state)
+}
+
+func (c *StreamingClient) startSubscription(ctx context.Context, sub Subscription) (*subscriptionState, error) {
+	if err := validateSubscription(sub); err != nil {
+		return nil, err
+	}
+
+	if sub.RequestID == "" {
+		sub.RequestID = c.newRequestID()
+	}
+
+	msg, err := subscriptionMessage("subscribe", sub)
+	if err != nil {
+		return nil, err
+	}
+
+	state, pending := c.register(sub)
+	if pending {
+		return state, nil
+	}
+
+	if err := c.traceSent(ctx, "subscribe", sub, msg); err != nil {
+		err = errors.Wrap(err, "can't subscribe to event")
+		c.unregister(subscriptionKeyOf(sub), sub.RequestID, err)
+		return nil, err
+	}
+	c.currentLogger().Log(LogDebug, "Subscribe request is sent",
+		Field{Key: "subscription", Value: subscriptionKeyOf(sub).String()},
+		Field{Key: "request_id", Value: sub.RequestID},
+	)
+
+	return state, nil
+}
+
+func (c *StreamingClient) waitSubscription(ctx context.Context, state *subscriptionState) error {
+	select {
+	case <-state.ack:
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "can't get subscription acknowledgement")
+	}
+
+	c.subsMu.Lock()
+	defer c.subsMu.Unlock()
+
+	return state.sub.Err
+}
+
+// unsubscribe sends unsubscribe request and removes subscription from the table.
+func (c *StreamingClient) unsubscribe(ctx context.Context, sub Subscription) error {
+	if err := validateSubscription(sub); err != nil {
+		return err
+	}
+
+	if sub.RequestID == "" {
+		sub.RequestID = c.newRequestID()
+	}
+
+	msg, err := subscriptionMessage("unsubscribe", sub)
+	if err != nil {
+		return err
+	}
+
+	if err := c.traceSent(ctx, "unsubscribe", sub, msg); err != nil {
+		return errors.Wrap(err, "can't unsubscribe from event")
+	}
+	c.currentLogger().Log(LogDebug, "Unsubscribe request is sent",
+		Field{Key: "subscription", Value: subscriptionKeyOf(sub).String()},
+		Field{Key: "request_id", Value: sub.RequestID},
+	)
+
+	c.unregister(subscriptionKeyOf(sub), "", ErrUnsubscribed)
+
+	return nil
+}
+
+// subscribeBatch validates all subscriptions and sends their requests without waiting for acknowledgement.
+func (c *StreamingClient) subscribeBatch(ctx context.Context, subs []Subscription) error {
+	for _, sub := range subs {
+		if err := validateSubscription(sub); err != nil {
+			return err
+		}
+	}
+
+	for _, sub := range subs {
+		if err := c.subscribe(ctx, sub, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unsubscribeBatch validates all subscriptions and sends their unsubscribe requests.
+func (c *StreamingClient) unsubscribeBatch(ctx context.Context, subs []Subscription) error {
+	for _, sub := range subs {
+		if err := validateSubscription(sub); err != nil {
+			return err
+		}
+	}
+
+	for _, sub := range subs {
+		if err := c.unsubscribe(ctx, sub); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// register adds pending subscription replacing active or failed one with the same key.
+// If subscription with the same key is pending, it is returned with pending set, so its request isn't sent twice.
+func (c *StreamingClient) register(sub Subscription) (state *subscriptionState, pending bool) {
+	sub.Status = SubscriptionPending
+	key := subscriptionKeyOf(sub)
+
+	c.subsMu.Lock()
+	defer c.subsMu.Unlock()
+
+	if prev, ok := c.subs[key]; ok {
+		if prev.sub.Status == SubscriptionPending {
+			return prev, true
+		}
+		delete(c.requests, prev.sub.RequestID)
+	}
+	state = &subscriptionState{sub: sub, ack: make(chan struct{})}
+	c.subs[key] = state
+	c.requests[sub.RequestID] = key
+
+	return state, false
+}
+
+// unregister removes subscription by key, if requestID is set subscription is removed only if it is still made by this request.
+// Pending subscription is failed by cause.
+func (c *StreamingClient) unregister(key subscriptionKey, requestID string, cause error) {
+	c.subsMu.Lock()
+	defer c.subsMu.Unlock()
+
+	state, ok := c.subs[key]
+	if !ok || (requestID != "" && state.sub.RequestID != requestID) {
+		return
+	}
+
+	delete(c.subs, key)
+	delete(c.requests, state.sub.RequestID)
+	c.currentMetrics().SubscriptionRemoved(key.String())
+	if state.sub.Status == SubscriptionPending {
+		state.settle(SubscriptionFailed, cause)
+	}
+}
+
+// trackSubscription updates subscription status by received event.
+func (c *StreamingClient) trackSubscription(event interface{}) {
+	c.subsMu.Lock()
+	defer c.subsMu.Unlock()
+
+	if e, ok := event.(ErrorEvent); ok {
+		key, ok := c.requests[e.Error.RequestID]
+		if !ok {
+			return
+		}
+		c.subs[key].settle(SubscriptionFailed, &StreamingError{RequestID: e.Error.RequestID, Message: e.Error.Error})
+		c.currentLogger().Log(LogWarn, "Subscription is failed",
+			Field{Key: "subscription", Value: key.String()},
+			Field{Key: "request_id", Value: e.Error.RequestID},
+			Field{Key: "error", Value: e.Error.Error},
+		)
+		return
+	}
+
+	if key, ok := eventSubscriptionKey(event); ok {
+		c.activate(key)
+	}
+}
+
+func (c *StreamingClient) activate(key subscriptionKey) {
+	if state, ok := c.subs[key]; ok && state.sub.Status == SubscriptionPending {
+		state.settle(SubscriptionActive, nil)
+		c.currentLogger().Log(LogDebug, "Subscription is active", Field{Key: "subscription", Value: key.String()})
+	}
+}
+
+// failPending marks all pending subscriptions as failed when read loop is finished.
+func (c *StreamingClient) failPending(err error) {
+	if err == nil {
+		err = ErrStreamingClosed
+	}
+
+	c.subsMu.Lock()
+	defer c.subsMu.Unlock()
+
+	for _, state := range c.subs {
+		if state.sub.Status == SubscriptionPending {
+			state.settle(SubscriptionFailed, err)
+		}
+	}
+}
+
+// settle must be called under subsMu lock.
+func (s *subscriptionState) settle(status SubscriptionStatus, err error) {
+	if s.sub.Status != SubscriptionPending && status == SubscriptionActive {
+		return
+	}
+
+	s.sub.Status = status
+	s.sub.Err = err
+
+	select {
+	case <-s.ack:
+	default:
+		close(s.ack)
+	}
+}
+
+// eventSubscriptionKey returns key of subscription which data event belongs to.
+func eventSubscriptionKey(event interface{}) (subscriptionKey, bool) {
+	switch e := event.(type) {
+	case CandleEvent:
+		return subscriptionKey{event: "candle", figi: e.Candle.FIGI, interval: e.Candle.Interval}, true
+	case OrderBookEvent:
+		return subscriptionKey{event: "orderbook", figi: e.OrderBook.FIGI, depth: e.OrderBook.Depth}, true
+	case InstrumentInfoEvent:
+		return subscriptionKey{event: "instrument_info", figi: e.Info.FIGI}, true
+	default:
+		return subscriptionKey{}, false
+	}
+}
+
+// String returns subscription name like candle:BBG005DXJS36:5min, orderbook:BBG005DXJS36:10 or instrument_info:BBG005DXJS36.
+func (k subscriptionKey) String() string {
+	switch k.event {
+	case "candle":
+		return k.event + ":" + k.figi + ":" + string(k.interval)
+	case "orderbook":
+		return k.event + ":" + k.figi + ":" + strconv.Itoa(k.depth)
+	default:
+		return k.event + ":" + k.figi
+	}
+}
+
+func subscriptionKeyOf(sub Subscription) subscriptionKey {
+	return subscriptionKey{event: sub.Event, figi: sub.FIGI, interval: sub.Interval, depth: sub.Depth}
+}
+
+func randomRequestIDPrefix() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand never fails on supported platforms, the counter still keeps ids unique within the client.
+		return "req"
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// validateSubscription checks figi format, candle interval and orderbook depth before request is sent.
+func validateSubscription(sub Subscription) error {
+	if !IsValidFIGI(sub.FIGI) {
+		return errors.Wrapf(ErrInvalidFIGI, "figi %q", sub.FIGI)
+	}
+
+	switch sub.Event {
+	case "candle":
+		if !isValidCandleInterval(sub.Interval) {
+			return errors.Wrapf(ErrInvalidInterval, "interval %q", sub.Interval)
+		}
+	case "orderbook":
+		if sub.Depth < 1 || sub.Depth > MaxOrderbookDepth {
+			return ErrDepth
+		}
+	}
+
+	return nil
+}
+
+// IsValidFIGI reports whether figi has FIGI format: 12 uppercase latin letters or digits.
+func IsValidFIGI(figi string) bool {
+	if len(figi) != figiLength {
+		return false
+	}
+
+	for i := 0; i < len(figi); i++ {
+		if (figi[i] < 'A' || figi[i] > 'Z') && (figi[i] < '0' || figi[i] > '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isValidCandleInterval reports whether interval is one of CandleInterval constants.
+func isValidCandleInterval(interval CandleInterval) bool {
+	switch interval {
+	case CandleInterval1Min, CandleInterval2Min, CandleInterval3Min, CandleInterval5Min,
+		CandleInterval10Min, CandleInterval15Min, CandleInterval30Min,
+		CandleInterval1Hour, CandleInterval2Hour, CandleInterval4Hour,
+		CandleInterval1Day, CandleInterval1Week, CandleInterval1Month:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,372 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	testFIGI         = "BBG000B9XRY4"
+	testRejectedFIGI = "BBG000000000"
+)
+
+// fakeStreamingServer acknowledges subscribe requests by the first event of subscription,
+// requests of rejected FIGIs are answered by error event.
+type fakeStreamingServer struct {
+	*httptest.Server
+
+	// ackDelay is delay before acknowledgement of subscribe request.
+	ackDelay time.Duration
+
+	dials int32
+
+	mu       sync.Mutex
+	requests []subscriptionRequest
+	conns    []*websocket.Conn
+}
+
+func newFakeStreamingServer(t *testing.T, ackDelay time.Duration) *fakeStreamingServer {
+	t.Helper()
+
+	s := &fakeStreamingServer{ackDelay: ackDelay}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(func() {
+		s.dropAll()
+		s.Close()
+	})
+
+	return s
+}
+
+func (s *fakeStreamingServer) url() string {
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+func (s *fakeStreamingServer) dial(t *testing.T, options ...StreamingOption) *StreamingClient {
+	t.Helper()
+
+	client, err := NewStreamingClient(nil, "token", append([]StreamingOption{WithStreamingURL(s.url())}, options...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+func (s *fakeStreamingServer) serve(w http.ResponseWriter, r *http.Request) {
+	conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	atomic.AddInt32(&s.dials, 1)
+	s.mu.Lock()
+	s.conns = append(s.conns, conn)
+	s.mu.Unlock()
+
+	// acks are written by single goroutine, gorilla forbids concurrent writes
+	acks := make(chan subscriptionRequest, 1024)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case req := <-acks:
+				select {
+				case <-time.After(s.ackDelay):
+				case <-done:
+					return
+				}
+				_ = conn.WriteMessage(websocket.TextMessage, ackMessage(req))
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req subscriptionRequest
+		if err := json.Unmarshal(msg, &req); err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.requests = append(s.requests, req)
+		s.mu.Unlock()
+
+		if strings.HasSuffix(req.Event, ":subscribe") {
+			acks <- req
+		}
+	}
+}
+
+// dropAll closes all connections on server side.
+func (s *fakeStreamingServer) dropAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+// subscribes returns count of received subscribe requests.
+func (s *fakeStreamingServer) subscribes() int {
+	return s.countRequests(":subscribe")
+}
+
+// unsubscribes returns count of received unsubscribe requests.
+func (s *fakeStreamingServer) unsubscribes() int {
+	return s.countRequests(":unsubscribe")
+}
+
+func (s *fakeStreamingServer) countRequests(action string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int
+	for _, req := range s.requests {
+		if strings.HasSuffix(req.Event, action) {
+			n++
+		}
+	}
+
+	return n
+}
+
+func ackMessage(req subscriptionRequest) []byte {
+	now := time.Now().UTC()
+
+	var event interface{}
+	switch {
+	case req.FIGI == testRejectedFIGI:
+		event = ErrorEvent{
+			FullEvent: FullEvent{Name: "error", Time: now},
+			Error:     Error{RequestID: req.RequestID, Error: "Subscription instrument not found"},
+		}
+	case strings.HasPrefix(req.Event, "candle"):
+		event = CandleEvent{FullEvent: FullEvent{Name: "candle", Time: now}, Candle: Candle{FIGI: req.FIGI, Interval: req.Interval, TS: now}}
+	case strings.HasPrefix(req.Event, "orderbook"):
+		event = OrderBookEvent{FullEvent: FullEvent{Name: "orderbook", Time: now}, OrderBook: OrderBook{FIGI: req.FIGI, Depth: req.Depth}}
+	default:
+		event = InstrumentInfoEvent{FullEvent: FullEvent{Name: "instrument_info", Time: now}, Info: InstrumentInfo{FIGI: req.FIGI}}
+	}
+
+	msg, _ := json.Marshal(event)
+
+	return msg
+}
+
+// runReadLoop runs read loop of client until the test ends and sends events to returned channel if it isn't full.
+func runReadLoop(t *testing.T, client *StreamingClient) <-chan interface{} {
+	t.Helper()
+
+	events := make(chan interface{}, 1024)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = client.RunReadLoopContext(ctx, func(event interface{}) error {
+			select {
+			case events <- event:
+			default:
+			}
+			return nil
+		})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	return events
+}
+
+func TestStreamingClientConcurrentWrites(t *testing.T) {
+	srv := newFakeStreamingServer(t, 0)
+	client := srv.dial(t, WithStreamingKeepalive(time.Millisecond, time.Second))
+	runReadLoop(t, client)
+
+	figis := []string{"BBG000B9XRY4", "BBG005DXJS36", "BBG004730N88", "BBG000BVPV84"}
+	intervals := []CandleInterval{CandleInterval1Min, CandleInterval5Min, CandleInterval1Hour, CandleInterval1Day}
+
+	var wg sync.WaitGroup
+	for _, figi := range figis {
+		for _, interval := range intervals {
+			wg.Add(1)
+			go func(figi string, interval CandleInterval) {
+				defer wg.Done()
+				if err := client.SubscribeCandleContext(context.Background(), figi, interval, ""); err != nil {
+					t.Error(err)
+				}
+				if err := client.UnsubscribeCandle(figi, interval, ""); err != nil {
+					t.Error(err)
+				}
+			}(figi, interval)
+		}
+	}
+	wg.Wait()
+
+	if n := srv.subscribes(); n != len(figis)*len(intervals) {
+		t.Fatalf("server received %d subscribe requests", n)
+	}
+	if subs := client.Subscriptions(); len(subs) != 0 {
+		t.Fatalf("subscriptions are left after unsubscribe: %+v", subs)
+	}
+}
+
+func TestStreamingClientCloseConcurrentWithSend(t *testing.T) {
+	srv := newFakeStreamingServer(t, 0)
+	client := srv.dial(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := client.SubscribeCandle(testFIGI, CandleInterval1Min, "")
+			if err != nil && !strings.Contains(err.Error(), ErrStreamingClosed.Error()) {
+				t.Error(err)
+			}
+		}()
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("second close: %v", err)
+	}
+	if err := client.SubscribeCandle(testFIGI, CandleInterval1Min, ""); err == nil {
+		t.Fatal("subscribe after close succeeded")
+	}
+}
+
+func TestStreamingClientSubscribeAck(t *testing.T) {
+	srv := newFakeStreamingServer(t, 0)
+	client := srv.dial(t)
+	runReadLoop(t, client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.SubscribeOrderbookContext(ctx, testFIGI, 5, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	err := client.SubscribeCandleContext(ctx, testRejectedFIGI, CandleInterval1Min, "rejected")
+	streamingErr, ok := err.(*StreamingError)
+	if !ok || streamingErr.RequestID != "rejected" {
+		t.Fatalf("want *StreamingError of request, got %v", err)
+	}
+
+	sub, ok := client.SubscriptionByRequestID("rejected")
+	if !ok || sub.Status != SubscriptionFailed || sub.Err != err {
+		t.Fatalf("got %+v", sub)
+	}
+}
+
+func TestStreamingClientConcurrentSubscribeSameKey(t *testing.T) {
+	srv := newFakeStreamingServer(t, 50*time.Millisecond)
+	client := srv.dial(t)
+	runReadLoop(t, client)
+
+	for _, figi := range []string{testFIGI, testRejectedFIGI} {
+		errs := make(chan error, 20)
+		for i := 0; i < cap(errs); i++ {
+			go func(figi string) {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				errs <- client.SubscribeCandleContext(ctx, figi, CandleInterval5Min, "")
+			}(figi)
+		}
+
+		for i := 0; i < cap(errs); i++ {
+			err := <-errs
+			if figi == testFIGI && err != nil {
+				t.Fatalf("subscription %d: %v", i, err)
+			}
+			if _, ok := err.(*StreamingError); figi == testRejectedFIGI && !ok {
+				t.Fatalf("subscription %d: want *StreamingError, got %v", i, err)
+			}
+		}
+	}
+}
+
+func TestStreamingClientPendingFailedOnReadLoopExit(t *testing.T) {
+	srv := newFakeStreamingServer(t, time.Hour)
+	client := srv.dial(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- client.RunReadLoopContext(ctx, func(interface{}) error { return nil })
+	}()
+
+	errs := make(chan error, 5)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			errs <- client.SubscribeCandleContext(context.Background(), testFIGI, CandleInterval1Min, "")
+		}()
+	}
+
+	// wait until all callers are waiting for the same request
+	for srv.subscribes() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	<-done
+
+	for i := 0; i < cap(errs); i++ {
+		select {
+		case err := <-errs:
+			if err == nil {
+				t.Fatal("pending subscription succeeded")
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("pending subscription isn't settled after read loop exit")
+		}
+	}
+}
+
+func TestStreamingClientSettersWhileUsed(t *testing.T) {
+	srv := newFakeStreamingServer(t, 0)
+	client := srv.dial(t)
+	runReadLoop(t, client)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			client.SetLogger(nopLogger{})
+			client.SetMetrics(NewPrometheusMetrics(""))
+			client.SetTracer(nopTracer{})
+		}
+	}()
+
+	for _, interval := range []CandleInterval{CandleInterval1Min, CandleInterval5Min, CandleInterval1Hour} {
+		if err := client.SubscribeCandleContext(context.Background(), testFIGI, interval, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+}
@@ -0,0 +1,219 @@
+package sdk
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultStaleTimeout is default time of silence after which candle or orderbook subscription is stale.
+const DefaultStaleTimeout = 2 * time.Minute
+
+// ErrStaleConnection is returned by StaleWatchdog.Run when stale connection is closed by StaleActionReconnect.
+var ErrStaleConnection = errors.New("stale streaming connection is closed")
+
+type StaleAction int
+
+const (
+	// StaleActionNotify only reports stale subscription.
+	StaleActionNotify StaleAction = iota
+	// StaleActionResubscribe sends unsubscribe and subscribe requests for stale subscription.
+	StaleActionResubscribe
+	// StaleActionReconnect closes the client, so its read loop returns error, and stops the watchdog
+	// with ErrStaleConnection. The caller must open new connection and subscribe again,
+	// subscriptions of the closed client are returned by its Subscriptions method.
+	StaleActionReconnect
+)
+
+type (
+	// StaleEvent is reported by StaleWatchdog when no candle or orderbook is received during timeout
+	// while instrument is expected to be traded.
+	StaleEvent struct {
+		Subscription Subscription
+		LastEvent    time.Time
+		Silence      time.Duration
+		TradeStatus  TradingStatus // empty if instrument info isn't subscribed
+		Action       StaleAction
+		Err          error // error of the action
+	}
+
+	// WatchdogConfig configures StaleWatchdog.
+	WatchdogConfig struct {
+		// Timeout of silence, DefaultStaleTimeout if zero.
+		Timeout time.Duration
+		// CheckInterval of subscriptions, Timeout/4 if zero.
+		CheckInterval time.Duration
+		// Action for stale subscription.
+		Action StaleAction
+		// OnStale is called for every stale subscription, may be nil.
+		OnStale func(StaleEvent)
+		// Calendar is used to skip silence while exchange of instrument is closed, may be nil.
+		// Silence is checked as usual, i.e. exchange is treated as open, when calendar returns error:
+		// exchange of instrument is unknown or holidays data is missing in strict mode.
+		// Holidays of years without data aren't known, so silence on them is reported.
+		Calendar *TradingCalendar
+	}
+
+	// StaleWatchdog tracks the last CandleEvent and OrderBookEvent of every subscription of single StreamingClient
+	// and raises StaleEvent after timeout of silence. Silence is expected and isn't reported while exchange
+	// of the instrument is closed by WatchdogConfig.Calendar or when the last InstrumentInfoEvent of the instrument
+	// has trade status other than NormalTrading. Without calendar and instrument info subscription
+	// stale subscriptions are reported every timeout outside trading hours.
+	StaleWatchdog struct {
+		client *StreamingClient
+		cfg    WatchdogConfig
+
+		mu          sync.Mutex
+		lastEvent   map[subscriptionKey]time.Time
+		tradeStatus map[string]TradingStatus
+	}
+)
+
+// NewStaleWatchdog returns watchdog of client subscriptions. Events must be passed to Observe or read loop handler
+// must be wrapped by Handler.
+func NewStaleWatchdog(client *StreamingClient, cfg WatchdogConfig) *StaleWatchdog {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultStaleTimeout
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = cfg.Timeout / 4
+	}
+
+	return &StaleWatchdog{
+		client:      client,
+		cfg:         cfg,
+		lastEvent:   make(map[subscriptionKey]time.Time),
+		tradeStatus: make(map[string]TradingStatus),
+	}
+}
+
+// Handler returns read loop handler which observes every event before passing it to next.
+func (w *StaleWatchdog) Handler(next func(event interface{}) error) func(event interface{}) error {
+	return func(event interface{}) error {
+		w.Observe(event)
+		return next(event)
+	}
+}
+
+// Observe updates the last event time of subscription and trade status of instrument.
+func (w *StaleWatchdog) Observe(event interface{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	switch e := event.(type) {
+	case CandleEvent, OrderBookEvent:
+		key, _ := eventSubscriptionKey(e)
+		w.lastEvent[key] = time.Now()
+	case InstrumentInfoEvent:
+		w.tradeStatus[e.Info.FIGI] = e.Info.TradeStatus
+	}
+}
+
+// Run checks subscriptions every check interval until ctx cancellation.
+func (w *StaleWatchdog) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			for _, event := range w.check(now) {
+				event.Err = w.act(ctx, event)
+				if w.cfg.OnStale != nil {
+					w.cfg.OnStale(event)
+				}
+				if event.Action == StaleActionReconnect {
+					return ErrStaleConnection
+				}
+			}
+		}
+	}
+}
+
+// check returns stale subscriptions, silence of reported subscription is counted again from now.
+func (w *StaleWatchdog) check(now time.Time) []StaleEvent {
+	subs := w.client.Subscriptions()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	watched := make(map[subscriptionKey]bool, len(subs))
+	var stale []StaleEvent
+
+	for _, sub := range subs {
+		if sub.Event == "instrument_info" || sub.Status == SubscriptionFailed {
+			continue
+		}
+
+		key := subscriptionKeyOf(sub)
+		watched[key] = true
+
+		last, ok := w.lastEvent[key]
+		if !ok {
+			// Silence of new subscription is counted from the first check.
+			w.lastEvent[key] = now
+			continue
+		}
+
+		status, known := w.tradeStatus[sub.FIGI]
+		if (known && status != NormalTrading) || w.isClosed(sub.FIGI, now) {
+			w.lastEvent[key] = now
+			continue
+		}
+
+		if silence := now.Sub(last); silence >= w.cfg.Timeout {
+			stale = append(stale, StaleEvent{
+				Subscription: sub,
+				LastEvent:    last,
+				Silence:      silence,
+				TradeStatus:  status,
+				Action:       w.cfg.Action,
+			})
+			w.lastEvent[key] = now
+		}
+	}
+
+	for key := range w.lastEvent {
+		if !watched[key] {
+			delete(w.lastEvent, key)
+		}
+	}
+
+	return stale
+}
+
+// isClosed reports whether exchange of instrument is closed by calendar, errors of calendar mean it is open.
+func (w *StaleWatchdog) isClosed(figi string, now time.Time) bool {
+	if w.cfg.Calendar == nil {
+		return false
+	}
+
+	open, err := w.cfg.Calendar.IsOpen(figi, now)
+
+	return err == nil && !open
+}
+
+func (w *StaleWatchdog) act(ctx context.Context, event StaleEvent) error {
+	switch event.Action {
+	case StaleActionResubscribe:
+		sub := event.Subscription
+		sub.RequestID = ""
+
+		if err := w.client.unsubscribe(ctx, sub); err != nil {
+			return errors.Wrap(err, "can't resubscribe")
+		}
+		if err := w.client.subscribe(ctx, sub, false); err != nil {
+			return errors.Wrap(err, "can't resubscribe")
+		}
+	case StaleActionReconnect:
+		if err := w.client.Close(); err != nil {
+			return errors.Wrap(err, "can't close stale connection")
+		}
+	}
+
+	return nil
+}
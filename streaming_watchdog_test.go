@@ -0,0 +1,78 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func subscribedClient(t *testing.T, figi string) *StreamingClient {
+	t.Helper()
+
+	srv := newFakeStreamingServer(t, 0)
+	client := srv.dial(t)
+	runReadLoop(t, client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.SubscribeCandleContext(ctx, figi, CandleInterval1Min, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
+
+func TestStaleWatchdogCalendar(t *testing.T) {
+	client := subscribedClient(t, testFIGIMOEX)
+
+	withCalendar := NewStaleWatchdog(client, WatchdogConfig{Timeout: time.Minute, Calendar: testCalendar()})
+	withoutCalendar := NewStaleWatchdog(client, WatchdogConfig{Timeout: time.Minute})
+
+	// Saturday
+	for _, w := range []*StaleWatchdog{withCalendar, withoutCalendar} {
+		w.check(msk(6, 12, 0))
+	}
+	if stale := withCalendar.check(msk(6, 13, 0)); len(stale) != 0 {
+		t.Fatalf("stale subscriptions are reported on weekend: %+v", stale)
+	}
+	if stale := withoutCalendar.check(msk(6, 13, 0)); len(stale) != 1 {
+		t.Fatalf("got %+v, want stale subscription without calendar", stale)
+	}
+
+	// silence is counted from the last check before opening at 9:50 on Tuesday
+	if stale := withCalendar.check(msk(9, 9, 49).Add(45 * time.Second)); len(stale) != 0 {
+		t.Fatalf("got %+v", stale)
+	}
+	if stale := withCalendar.check(msk(9, 9, 50).Add(30 * time.Second)); len(stale) != 0 {
+		t.Fatalf("got %+v", stale)
+	}
+	stale := withCalendar.check(msk(9, 9, 52))
+	if len(stale) != 1 || stale[0].Subscription.FIGI != testFIGIMOEX {
+		t.Fatalf("got %+v, want stale subscription during session", stale)
+	}
+}
+
+func TestStaleWatchdogReconnect(t *testing.T) {
+	client := subscribedClient(t, testFIGI)
+
+	var events []StaleEvent
+	w := NewStaleWatchdog(client, WatchdogConfig{
+		Timeout:       20 * time.Millisecond,
+		CheckInterval: 5 * time.Millisecond,
+		Action:        StaleActionReconnect,
+		OnStale:       func(event StaleEvent) { events = append(events, event) },
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := w.Run(ctx); !errors.Is(err, ErrStaleConnection) {
+		t.Fatalf("want ErrStaleConnection, got %v", err)
+	}
+	if len(events) != 1 || events[0].Err != nil {
+		t.Fatalf("got %+v", events)
+	}
+	if err := client.SubscribeCandle(testFIGI, CandleInterval5Min, ""); err == nil {
+		t.Fatal("stale connection isn't closed")
+	}
+}
@@ -0,0 +1,226 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// Directions of streaming messages.
+const (
+	MessageSent     = "sent"
+	MessageReceived = "received"
+)
+
+type (
+	// Tracer is hook around every REST request and streaming message, it may start spans of tracing system.
+	// Implementations must be safe for concurrent use.
+	Tracer interface {
+		// StartRequest is called before provider call, returned ctx is passed to the provider.
+		StartRequest(ctx context.Context, info RequestInfo) (context.Context, RequestSpan)
+		// StartMessage is called before subscription request is sent and before received event is passed to handler.
+		// Ctx of received event is ctx of RunReadLoopContext, returned ctx isn't passed to handler.
+		StartMessage(ctx context.Context, info MessageInfo) (context.Context, MessageSpan)
+	}
+
+	// RequestSpan is finished when provider call returns.
+	RequestSpan interface {
+		End(result RequestResult)
+	}
+
+	// MessageSpan is finished when message is sent or handler returns.
+	MessageSpan interface {
+		End(err error)
+	}
+
+	// RequestInfo describes REST request.
+	RequestInfo struct {
+		Endpoint  string // RestClient method name, e.g. LimitOrder
+		Method    string
+		Path      string // url path without query
+		AccountID string
+		FIGI      string
+	}
+
+	// RequestResult describes REST response.
+	RequestResult struct {
+		StatusCode int // 0 if request isn't completed
+		Status     string
+		TrackingID string
+		Err        error
+	}
+
+	// MessageInfo describes streaming message.
+	MessageInfo struct {
+		Direction    string // MessageSent or MessageReceived
+		Event        string // e.g. candle:subscribe for sent message, candle for received one
+		Subscription string // e.g. candle:BBG005DXJS36:5min
+		RequestID    string
+		FIGI         string
+	}
+
+	nopTracer      struct{}
+	nopRequestSpan struct{}
+	nopMessageSpan struct{}
+
+	// responseEnvelope is embedded in response targets, so tracing gets tracking id from decoded response.
+	responseEnvelope struct {
+		TrackingID string `json:"trackingId"`
+		Status     string `json:"status"`
+	}
+
+	envelopedResponse interface {
+		envelope() responseEnvelope
+	}
+)
+
+var (
+	_ Tracer      = nopTracer{}
+	_ RequestSpan = nopRequestSpan{}
+	_ MessageSpan = nopMessageSpan{}
+
+	_ envelopedResponse = &responseEnvelope{}
+)
+
+func (nopTracer) StartRequest(ctx context.Context, _ RequestInfo) (context.Context, RequestSpan) {
+	return ctx, nopRequestSpan{}
+}
+
+func (nopTracer) StartMessage(ctx context.Context, _ MessageInfo) (context.Context, MessageSpan) {
+	return ctx, nopMessageSpan{}
+}
+
+func (nopRequestSpan) End(RequestResult) {}
+func (nopMessageSpan) End(error)         {}
+
+// WithTracer build rest client with tracer of every provider call.
+func WithTracer(tracer Tracer) BuildOption {
+	return func(client *RestClient) {
+		client.tracer = tracer
+	}
+}
+
+// SetTracer sets tracer of subscription requests and received events,
+// it is safe to call it while connection is used, prefer WithStreamingTracer option.
+func (c *StreamingClient) SetTracer(tracer Tracer) {
+	c.settingsMu.Lock()
+	defer c.settingsMu.Unlock()
+
+	c.tracer = tracer
+}
+
+func (c *StreamingClient) currentTracer() Tracer {
+	c.settingsMu.RLock()
+	defer c.settingsMu.RUnlock()
+
+	return c.tracer
+}
+
+// SetTracer sets tracer for connections of the pool opened after the call.
+func (p *StreamingPool) SetTracer(tracer Tracer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.tracer = tracer
+}
+
+func (e *responseEnvelope) envelope() responseEnvelope {
+	return *e
+}
+
+func (c *RestClient) get(ctx context.Context, info RequestInfo, path string, unmarshal interface{}) error {
+	info.Method = http.MethodGet
+
+	ctx, span := c.startRequest(ctx, &info, path)
+	err := c.handler(ctx, &RestRequest{RequestInfo: info, URL: path, Token: c.token, Unmarshal: unmarshal})
+	span.End(requestResult(unmarshal, err))
+
+	return err
+}
+
+func (c *RestClient) post(ctx context.Context, info RequestInfo, path string, payload, unmarshal interface{}) error {
+	info.Method = http.MethodPost
+
+	ctx, span := c.startRequest(ctx, &info, path)
+
+	// Response without payload isn't decoded at all, so its tracking id is known for errors only.
+	err := c.handler(ctx, &RestRequest{RequestInfo: info, URL: path, Token: c.token, Payload: payload, Unmarshal: unmarshal})
+	span.End(requestResult(unmarshal, err))
+
+	return err
+}
+
+// startRequest completes info by path and starts span of request.
+func (c *RestClient) startRequest(ctx context.Context, info *RequestInfo, path string) (context.Context, RequestSpan) {
+	if u, err := url.Parse(path); err == nil {
+		info.Path = u.Path
+	}
+	if info.AccountID == DefaultAccount {
+		info.AccountID = ""
+	}
+
+	return c.tracer.StartRequest(ctx, *info)
+}
+
+// requestResult returns result of request, tracking id of successful response is read from target if it embeds responseEnvelope.
+func requestResult(target interface{}, err error) RequestResult {
+	if err == nil {
+		result := RequestResult{StatusCode: http.StatusOK}
+		if response, ok := target.(envelopedResponse); ok {
+			envelope := response.envelope()
+			result.Status = envelope.Status
+			result.TrackingID = envelope.TrackingID
+		}
+		return result
+	}
+
+	result := RequestResult{Err: err}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		result.StatusCode = apiErr.StatusCode
+		result.TrackingID = apiErr.TrackingID
+	}
+
+	var tradingErr TradingError
+	if errors.As(err, &tradingErr) {
+		result.Status = tradingErr.Status
+		result.TrackingID = tradingErr.TrackingID
+	}
+
+	return result
+}
+
+// traceSent sends message within span of the tracer.
+func (c *StreamingClient) traceSent(ctx context.Context, action string, sub Subscription, msg []byte) error {
+	ctx, span := c.currentTracer().StartMessage(ctx, MessageInfo{
+		Direction:    MessageSent,
+		Event:        sub.Event + ":" + action,
+		Subscription: subscriptionKeyOf(sub).String(),
+		RequestID:    sub.RequestID,
+		FIGI:         sub.FIGI,
+	})
+	err := c.send(ctx, msg)
+	span.End(err)
+
+	return err
+}
+
+// traceReceived calls handler within span of the tracer.
+func (c *StreamingClient) traceReceived(ctx context.Context, event interface{}, name string, fn func(event interface{}) error) error {
+	info := MessageInfo{Direction: MessageReceived, Event: name}
+	if key, ok := eventSubscriptionKey(event); ok {
+		info.Subscription = key.String()
+		info.FIGI = key.figi
+	}
+	if e, ok := event.(ErrorEvent); ok {
+		info.RequestID = e.Error.RequestID
+	}
+
+	_, span := c.currentTracer().StartMessage(ctx, info)
+	err := fn(event)
+	span.End(err)
+
+	return err
+}
@@ -0,0 +1,74 @@
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type recordTracer struct {
+	nopTracer
+
+	mu      sync.Mutex
+	results []RequestResult
+}
+
+type recordRequestSpan struct {
+	tracer *recordTracer
+}
+
+func (t *recordTracer) StartRequest(ctx context.Context, _ RequestInfo) (context.Context, RequestSpan) {
+	return ctx, recordRequestSpan{tracer: t}
+}
+
+func (s recordRequestSpan) End(result RequestResult) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+
+	s.tracer.results = append(s.tracer.results, result)
+}
+
+// targetProvider records types of decoding targets passed to the provider.
+type targetProvider struct {
+	Provider
+
+	targets []reflect.Type
+}
+
+func (p *targetProvider) Get(ctx context.Context, url string, token string, unmarshal interface{}) error {
+	p.targets = append(p.targets, reflect.TypeOf(unmarshal))
+
+	return p.Provider.Get(ctx, url, token, unmarshal)
+}
+
+func TestTracerTrackingID(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"trackingId":"abc","status":"Ok","payload":{"figi":"BBG000B9XRY4","ticker":"AAPL"}}`))
+	}))
+	defer srv.Close()
+
+	tracer := &recordTracer{}
+	provider := &targetProvider{Provider: &defaultHTTP{client: srv.Client(), logger: nopLogger{}}}
+	client := NewRestClient("token", WithURL(srv.URL), WithProvider(provider), WithTracer(tracer))
+
+	instrument, err := client.InstrumentByFIGI(context.Background(), "BBG000B9XRY4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if instrument.Ticker != "AAPL" {
+		t.Fatalf("got %+v", instrument)
+	}
+
+	want := RequestResult{StatusCode: http.StatusOK, Status: "Ok", TrackingID: "abc"}
+	if len(tracer.results) != 1 || tracer.results[0] != want {
+		t.Fatalf("got %+v, want %+v", tracer.results, want)
+	}
+
+	// provider decodes into target of RestClient method, not into wrapper of tracer
+	if len(provider.targets) != 1 || provider.targets[0].Elem().Field(1).Name != "Payload" {
+		t.Fatalf("provider got targets %v", provider.targets)
+	}
+}